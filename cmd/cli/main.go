@@ -2,15 +2,29 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
+	"os"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/shinychan95/Chan/sync"
 )
 
 func main() {
+	// 하위 명령어: chan [sync] | chan snapshot | chan clean-images
+	if len(os.Args) > 1 && os.Args[1] == "snapshot" {
+		runSnapshot(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "clean-images" {
+		runCleanImages(os.Args[2:])
+		return
+	}
+
 	// flag
 	configPath := flag.String("config", "config.json", "Path to config.json file")
+	full := flag.Bool("full", false, "Re-export every page, ignoring the manifest of the last sync")
+	flag.Usage = usage
 	flag.Parse()
 
 	log.Println("Notion Blog CLI 시작")
@@ -23,11 +37,69 @@ func main() {
 
 	// 동기화 실행
 	log.Println("블로그 동기화 시작...")
-	result := syncer.SyncToBlog()
+	result := syncer.SyncToBlog(*full)
+
+	if result.Report != nil {
+		fmt.Print(result.Report)
+	}
 
 	if result.Success {
-		log.Printf("✅ 동기화 완료! (소요시간: %s)", result.Duration)
+		log.Printf("✅ %s! (글 %d개, 이미지 %d개, 소요시간: %s)", result.Message, result.PostCount, result.ImageCount, result.Duration)
 	} else {
 		log.Fatalf("❌ 동기화 실패: %s (오류: %v)", result.Message, result.Error)
 	}
 }
+
+// runSnapshot 현재 notion.db 로부터 JSON 스냅샷을 기록합니다.
+func runSnapshot(args []string) {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "Path to config.json file")
+	outDir := fs.String("out", "snapshot", "Directory to write the snapshot to")
+	fs.Parse(args)
+
+	syncer, err := sync.NewBlogSyncer(*configPath)
+	if err != nil {
+		log.Fatalf("Config 로드 실패: %v", err)
+	}
+
+	log.Printf("스냅샷 기록 시작... (%s)", *outDir)
+	if err = syncer.Snapshot(*outDir); err != nil {
+		log.Fatalf("❌ 스냅샷 기록 실패: %v", err)
+	}
+	log.Printf("✅ 스냅샷 기록 완료: %s", *outDir)
+}
+
+// runCleanImages 어떤 글도 사용하지 않는 이미지를 보여주거나(-dry-run) 지웁니다.
+func runCleanImages(args []string) {
+	fs := flag.NewFlagSet("clean-images", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "Path to config.json file")
+	dryRun := fs.Bool("dry-run", false, "List orphaned images without removing them")
+	fs.Parse(args)
+
+	syncer, err := sync.NewBlogSyncer(*configPath)
+	if err != nil {
+		log.Fatalf("Config 로드 실패: %v", err)
+	}
+
+	orphans, err := syncer.OrphanedImages(*dryRun)
+	if err != nil {
+		log.Fatalf("❌ 이미지 정리 실패: %v", err)
+	}
+
+	for _, orphan := range orphans {
+		fmt.Println(orphan)
+	}
+	if *dryRun {
+		log.Printf("사용하지 않는 이미지 %d개 (지우지 않음)", len(orphans))
+	} else {
+		log.Printf("✅ 사용하지 않는 이미지 %d개를 지웠습니다", len(orphans))
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n")
+	fmt.Fprintf(os.Stderr, "  chan [-config config.json] [-full]               블로그 동기화 (-full: 바뀌지 않은 글도 다시 변환)\n")
+	fmt.Fprintf(os.Stderr, "  chan snapshot [-config config.json] [-out dir]   notion.db 의 JSON 스냅샷 기록\n")
+	fmt.Fprintf(os.Stderr, "  chan clean-images [-config config.json] [-dry-run]  사용하지 않는 이미지 정리 (-dry-run: 목록만 출력)\n\n")
+	flag.PrintDefaults()
+}
@@ -12,13 +12,33 @@ import (
 )
 
 type Config struct {
-	DBPath      string `json:"db_path"`
-	ApiKey      string `json:"api_key"`
-	PostDir     string `json:"post_directory"`
-	ImgDir      string `json:"image_directory"`
-	RootID      string `json:"root_id"`
-	GitHubToken string `json:"github_token"`
-	GitHubRepo  string `json:"github_repo"` // 예: "shinychan95/shinychan95.github.io"
+	Source          string `json:"source,omitempty"` // sqlite(기본값, notion.db) | api(Notion 공개 API) | snapshot(JSON 덤프)
+	DBPath          string `json:"db_path"`
+	SnapshotDir     string `json:"snapshot_directory,omitempty"`
+	ApiKey          string `json:"api_key"`
+	PostDir         string `json:"post_directory"`
+	ImgDir          string `json:"image_directory"`
+	RootID          string `json:"root_id"` // collection view 혹은 일반 페이지 ID
+	IncludeSubPages bool   `json:"include_sub_pages,omitempty"`
+	InlineMath      string `json:"inline_math,omitempty"` // 수식 구분자 (기본값: $...$)
+	BlockMath       string `json:"block_math,omitempty"`  // 블록 수식 구분자 (기본값: $$...$$)
+	// 단일 루트의 변환 설정 (roots 항목과 같은 의미)
+	EmbedIncludes       map[string]string `json:"embed_includes,omitempty"`
+	CalloutPrompts      map[string]string `json:"callout_prompts,omitempty"`
+	ColorStyle          string            `json:"color_style,omitempty"` // class(기본값) | inline | none
+	Stylesheet          string            `json:"stylesheet,omitempty"`
+	ExpandToggleHeaders bool              `json:"expand_toggle_headers,omitempty"`
+	ImageCaptionStyle   string            `json:"image_caption_style,omitempty"` // chirpy(기본값) | figure
+	FlattenColumns      bool              `json:"flatten_columns,omitempty"`
+	// 여러 루트를 각각 다른 폴더로 내보내는 경우 (예: _posts, _til, _notes)
+	Roots       []utils.RootConfig `json:"roots,omitempty"`
+	GitHubToken string             `json:"github_token"`
+	GitHubRepo  string             `json:"github_repo"`           // 예: "shinychan95/shinychan95.github.io"
+	ReportPath  string             `json:"report_path,omitempty"` // 기본값: 설정 폴더/last-sync.json
+	// 바뀐 글만 다시 변환하기 위한 글 상태 파일 (기본값: 설정 폴더/manifest.json)
+	ManifestPath string `json:"manifest_path,omitempty"`
+	// 동기화할 때 어떤 글도 사용하지 않는 이미지를 지울지 여부
+	CleanImages bool `json:"clean_images,omitempty"`
 }
 
 // GetConfigPath returns the path to the config file in user's Application Support
@@ -139,28 +159,51 @@ func CreateDefaultConfig() *Config {
 func ValidateConfig(cfg *Config) []string {
 	var errors []string
 
-	if cfg.ApiKey == "" {
+	if cfg.ToUtilsConfig().UsesNotionAPI() && cfg.ApiKey == "" {
 		errors = append(errors, "Notion API Key가 설정되지 않았습니다")
 	}
 
-	if cfg.RootID == "" {
+	if cfg.RootID == "" && len(cfg.Roots) == 0 {
 		errors = append(errors, "Collection View ID가 설정되지 않았습니다")
 	}
 
-	if cfg.DBPath == "" {
-		errors = append(errors, "Notion DB 경로가 설정되지 않았습니다")
-	} else if _, err := os.Stat(cfg.DBPath); os.IsNotExist(err) {
-		errors = append(errors, fmt.Sprintf("Notion DB가 존재하지 않습니다: %s", cfg.DBPath))
+	switch cfg.Source {
+	case "", "sqlite":
+		if cfg.DBPath == "" {
+			errors = append(errors, "Notion DB 경로가 설정되지 않았습니다")
+		} else if _, err := os.Stat(cfg.DBPath); os.IsNotExist(err) {
+			errors = append(errors, fmt.Sprintf("Notion DB가 존재하지 않습니다: %s", cfg.DBPath))
+		}
+	case "api":
+		// Notion 공개 API 사용 시 notion.db 가 필요하지 않음
+	case "snapshot":
+		if cfg.SnapshotDir == "" {
+			errors = append(errors, "Snapshot Directory가 설정되지 않았습니다")
+		} else if _, err := os.Stat(cfg.SnapshotDir); os.IsNotExist(err) {
+			errors = append(errors, fmt.Sprintf("Snapshot Directory가 존재하지 않습니다: %s", cfg.SnapshotDir))
+		}
+	default:
+		errors = append(errors, fmt.Sprintf("지원하지 않는 source 입니다: %s", cfg.Source))
 	}
 
-	if cfg.PostDir == "" {
-		errors = append(errors, "Post Directory가 설정되지 않았습니다")
-	} else if _, err := os.Stat(cfg.PostDir); os.IsNotExist(err) {
-		errors = append(errors, fmt.Sprintf("Post Directory가 존재하지 않습니다: %s", cfg.PostDir))
-	}
+	for _, root := range cfg.ToUtilsConfig().AllRoots() {
+		if root.RootID == "" {
+			errors = append(errors, "루트 ID가 설정되지 않은 roots 항목이 있습니다")
+		}
+
+		if root.PostDir == "" {
+			errors = append(errors, "Post Directory가 설정되지 않았습니다")
+		} else if _, err := os.Stat(root.PostDir); os.IsNotExist(err) {
+			errors = append(errors, fmt.Sprintf("Post Directory가 존재하지 않습니다: %s", root.PostDir))
+		}
 
-	if cfg.ImgDir == "" {
-		errors = append(errors, "Image Directory가 설정되지 않았습니다")
+		if root.ImgDir == "" {
+			errors = append(errors, "Image Directory가 설정되지 않았습니다")
+		}
+
+		if !root.ValidMathDelimiters() {
+			errors = append(errors, fmt.Sprintf("수식 구분자에 %q 가 없습니다: %s", utils.MathPlaceholder, root.RootID))
+		}
 	}
 
 	return errors
@@ -180,14 +223,40 @@ func OpenConfigInEditor() error {
 
 // ToUtilsConfig converts config.Config to utils.Config for compatibility
 func (c *Config) ToUtilsConfig() *utils.Config {
+	configDir := filepath.Dir(GetConfigPath())
+	reportPath := c.ReportPath
+	if reportPath == "" {
+		reportPath = filepath.Join(configDir, "last-sync.json")
+	}
+	manifestPath := c.ManifestPath
+	if manifestPath == "" {
+		manifestPath = filepath.Join(configDir, "manifest.json")
+	}
+
 	return &utils.Config{
-		DBPath:      c.DBPath,
-		ApiKey:      c.ApiKey,
-		PostDir:     c.PostDir,
-		ImgDir:      c.ImgDir,
-		RootID:      c.RootID,
-		GitHubToken: c.GitHubToken,
-		GitHubRepo:  c.GitHubRepo,
+		Source:              c.Source,
+		DBPath:              c.DBPath,
+		SnapshotDir:         c.SnapshotDir,
+		ApiKey:              c.ApiKey,
+		PostDir:             c.PostDir,
+		ImgDir:              c.ImgDir,
+		RootID:              c.RootID,
+		IncludeSubPages:     c.IncludeSubPages,
+		InlineMath:          c.InlineMath,
+		BlockMath:           c.BlockMath,
+		EmbedIncludes:       c.EmbedIncludes,
+		CalloutPrompts:      c.CalloutPrompts,
+		ColorStyle:          c.ColorStyle,
+		Stylesheet:          c.Stylesheet,
+		ExpandToggleHeaders: c.ExpandToggleHeaders,
+		ImageCaptionStyle:   c.ImageCaptionStyle,
+		FlattenColumns:      c.FlattenColumns,
+		Roots:               c.Roots,
+		GitHubToken:         c.GitHubToken,
+		GitHubRepo:          c.GitHubRepo,
+		ReportPath:          reportPath,
+		ManifestPath:        manifestPath,
+		CleanImages:         c.CleanImages,
 	}
 }
 
@@ -92,11 +92,20 @@ func refreshTrayMenu() {
 		if isConfigured {
 			menuItems = append(menuItems, fyne.NewMenuItemSeparator(), fyne.NewMenuItem("Notion 동기화", func() {
 				if isConfigured {
-					go handleSync()
+					go handleSync(false)
 					showNotification("시작", "동기화를 시작합니다...")
 				} else {
 					showNotification("설정 필요", "먼저 설정을 완료해주세요")
 				}
+			}), fyne.NewMenuItem("전체 다시 동기화", func() {
+				if isConfigured {
+					go handleSync(true)
+					showNotification("시작", "모든 글을 다시 동기화합니다...")
+				} else {
+					showNotification("설정 필요", "먼저 설정을 완료해주세요")
+				}
+			}), fyne.NewMenuItem("마지막 동기화 결과", func() {
+				showReportWindow()
 			}))
 		}
 
@@ -135,6 +144,34 @@ func showMainWindow() {
 	mainWindow.RequestFocus()
 }
 
+// showReportWindow 마지막 동기화의 글별 결과(저장한 파일, 이미지, 경고, 오류)를 보여줍니다.
+func showReportWindow() {
+	result := syncer.GetStatus().LastResult
+	if result == nil {
+		showNotification("알림", "아직 동기화 결과가 없습니다")
+		return
+	}
+
+	summary := fmt.Sprintf("%s (글 %d개, 이미지 %d개, 소요시간: %s)", result.Message, result.PostCount, result.ImageCount, formatDuration(result.Duration))
+	if result.Error != nil {
+		summary += fmt.Sprintf("\n오류: %v", result.Error)
+	}
+	details := "글별 결과가 없습니다."
+	if result.Report != nil && len(result.Report.Pages) > 0 {
+		details = result.Report.String()
+	}
+
+	text := widget.NewMultiLineEntry()
+	text.SetText(details)
+	text.Wrapping = fyne.TextWrapWord
+
+	win := fyneApp.NewWindow("마지막 동기화 결과")
+	win.Resize(fyne.NewSize(700, 500))
+	win.CenterOnScreen()
+	win.SetContent(container.NewBorder(widget.NewLabel(summary), nil, nil, nil, text))
+	win.Show()
+}
+
 func loadConfiguration() {
 	var err error
 	cfg, err = config.LoadConfig()
@@ -340,7 +377,7 @@ func createNotionSettingsUI(win fyne.Window) fyne.CanvasObject {
 
 	syncButton = widget.NewButton("🦤 동기화 실행", func() {
 		if isConfigured {
-			go handleSync()
+			go handleSync(false)
 			showNotification("시작", "동기화를 시작합니다...")
 		} else {
 			showNotification("설정 필요", "먼저 설정을 완료해주세요")
@@ -373,7 +410,8 @@ func createNotionSettingsUI(win fyne.Window) fyne.CanvasObject {
 	return form
 }
 
-func handleSync() {
+// handleSync 동기화를 실행합니다. full 이면 바뀌지 않은 글도 모두 다시 변환합니다.
+func handleSync(full bool) {
 	if !isConfigured || syncer == nil {
 		showNotification("오류", "설정을 먼저 완료해주세요")
 		return
@@ -395,9 +433,12 @@ func handleSync() {
 		}()
 
 		showNotification("시작", "🦤 블로그 동기화를 시작합니다")
-		result := syncer.SyncToBlog()
+		result := syncer.SyncToBlog(full)
 		if result.Success {
-			msg := fmt.Sprintf("동기화 완료! (소요시간: %s)", formatDuration(result.Duration))
+			msg := fmt.Sprintf("동기화 완료! (글 %d개, 소요시간: %s)", result.PostCount, formatDuration(result.Duration))
+			if len(result.Report.Failures) > 0 {
+				msg = fmt.Sprintf("동기화 완료, %d개 글 실패 (소요시간: %s)", len(result.Report.Failures), formatDuration(result.Duration))
+			}
 			showNotification("완료", msg)
 		} else {
 			showNotification("오류", result.Message)
@@ -0,0 +1,119 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 색상 표현 방식
+const (
+	ColorStyleClass  = "class"  // <span class="notion-red">, 함께 생성하는 CSS 파일 필요
+	ColorStyleInline = "inline" // <span style="color: #D44C47">
+	ColorStyleNone   = "none"   // 색상 무시
+)
+
+type NotionColor struct {
+	Name       string
+	Text       string
+	Background string
+}
+
+// NotionColors 노션(라이트 테마)의 색상 팔레트
+var NotionColors = []NotionColor{
+	{Name: "gray", Text: "#787774", Background: "#F1F1EF"},
+	{Name: "brown", Text: "#9F6B53", Background: "#F4EEEE"},
+	{Name: "orange", Text: "#D9730D", Background: "#FBECDD"},
+	{Name: "yellow", Text: "#CB912F", Background: "#FBF3DB"},
+	{Name: "green", Text: "#448361", Background: "#EDF3EC"},
+	{Name: "blue", Text: "#337EA9", Background: "#E7F3F8"},
+	{Name: "purple", Text: "#9065B0", Background: "#F6F3F9"},
+	{Name: "pink", Text: "#C14C8A", Background: "#FAF1F5"},
+	{Name: "red", Text: "#D44C47", Background: "#FDEBEC"},
+}
+
+// lookupColor "red", "yellow_background" 형식의 노션 색상을 찾습니다.
+func lookupColor(color string) (c NotionColor, background bool, ok bool) {
+	name := strings.TrimSuffix(color, "_background")
+	for _, c = range NotionColors {
+		if c.Name == name {
+			return c, name != color, true
+		}
+	}
+	return NotionColor{}, false, false
+}
+
+// Color 텍스트에 글자색(<span>) 혹은 배경색(<mark>)을 입힙니다.
+func Color(text, color, style string) string {
+	c, background, ok := lookupColor(color)
+	if !ok || text == "" {
+		return text
+	}
+
+	switch {
+	case style == ColorStyleNone:
+		return text
+	case style == ColorStyleInline && background:
+		return fmt.Sprintf("<mark style=\"background-color: %s\">%s</mark>", c.Background, text)
+	case style == ColorStyleInline:
+		return fmt.Sprintf("<span style=\"color: %s\">%s</span>", c.Text, text)
+	case background:
+		return fmt.Sprintf("<mark class=\"notion-%s-bg\">%s</mark>", c.Name, text)
+	default:
+		return fmt.Sprintf("<span class=\"notion-%s\">%s</span>", c.Name, text)
+	}
+}
+
+// ColorAttribute 문단 등 블록 전체에 색상을 입히는 kramdown 속성 목록(IAL)입니다. 예: {: .notion-red }
+func ColorAttribute(indent, color, style string) string {
+	c, background, ok := lookupColor(color)
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case style == ColorStyleNone:
+		return ""
+	case style == ColorStyleInline && background:
+		return fmt.Sprintf("%s{: style=\"background-color: %s\" }\n", indent, c.Background)
+	case style == ColorStyleInline:
+		return fmt.Sprintf("%s{: style=\"color: %s\" }\n", indent, c.Text)
+	case background:
+		return fmt.Sprintf("%s{: .notion-%s-bg }\n", indent, c.Name)
+	default:
+		return fmt.Sprintf("%s{: .notion-%s }\n", indent, c.Name)
+	}
+}
+
+// ColorHTMLAttribute HTML 요소(표의 셀 등)에 색상을 입히는 속성입니다. 예: ` class="notion-red"`
+func ColorHTMLAttribute(color, style string) string {
+	c, background, ok := lookupColor(color)
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case style == ColorStyleNone:
+		return ""
+	case style == ColorStyleInline && background:
+		return fmt.Sprintf(" style=\"background-color: %s\"", c.Background)
+	case style == ColorStyleInline:
+		return fmt.Sprintf(" style=\"color: %s\"", c.Text)
+	case background:
+		return fmt.Sprintf(" class=\"notion-%s-bg\"", c.Name)
+	default:
+		return fmt.Sprintf(" class=\"notion-%s\"", c.Name)
+	}
+}
+
+// ColorCSS class 방식에서 사용하는 노션 색상 팔레트 CSS 입니다.
+func ColorCSS() string {
+	var sb strings.Builder
+
+	sb.WriteString("/* 노션 색상 팔레트 */\n")
+	for _, c := range NotionColors {
+		sb.WriteString(fmt.Sprintf(".notion-%s { color: %s; }\n", c.Name, c.Text))
+		sb.WriteString(fmt.Sprintf(".notion-%s-bg { background-color: %s; }\n", c.Name, c.Background))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,63 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 본문의 일반 텍스트는 마크다운/HTML/Liquid 문법으로 해석되지 않도록 HTML 엔티티로 바꾼다.
+// 백슬래시 이스케이프와 달리 엔티티는 마크다운 문단, 표, 링크 텍스트뿐 아니라 HTML 요소(<h1>, <summary> 등) 안에서도 같은 글자로 보인다.
+// 코드와 수식은 글자 그대로 보여야 하므로 이스케이프하지 않고, Liquid 문법이 있으면 {% raw %} 로 감싼다.
+
+var escaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"\\", "&#92;",
+	"*", "&#42;",
+	"_", "&#95;",
+	"`", "&#96;",
+	"~", "&#126;",
+	"[", "&#91;",
+	"]", "&#93;",
+	"$", "&#36;", // kramdown 수식($$), MathJax 인라인 수식($)
+	"{", "&#123;", // Liquid({{, {%), kramdown 속성({: ...})
+	"}", "&#125;",
+)
+
+// lineStartPattern 줄 맨 앞에서 제목, 목록, 구분선 등으로 해석되는 문자
+var lineStartPattern = regexp.MustCompile(`(?m)^([ \t]*)(?:([#+=-])|(\d+)([.)]))`)
+
+// Escape 일반 텍스트를 마크다운에서 글자 그대로 보이도록 이스케이프합니다.
+// 텍스트가 줄 중간에 붙더라도 엔티티는 같은 글자로 보이므로, 텍스트의 시작과 줄바꿈 다음은 항상 줄 맨 앞으로 취급한다.
+func Escape(text string) string {
+	text = escaper.Replace(text)
+
+	return lineStartPattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := lineStartPattern.FindStringSubmatch(match)
+		if groups[2] != "" {
+			return fmt.Sprintf("%s&#%d;", groups[1], groups[2][0])
+		}
+		return fmt.Sprintf("%s%s&#%d;", groups[1], groups[3], groups[4][0])
+	})
+}
+
+// ContainsLiquid Jekyll 의 Liquid 가 실행하는 문법({{ }}, {% %})이 있는지 확인합니다.
+func ContainsLiquid(text string) bool {
+	return strings.Contains(text, "{{") || strings.Contains(text, "{%")
+}
+
+// CanRawLiquid {% raw %} 로 감쌀 수 있는지 확인합니다. (텍스트에 endraw 가 있으면 감쌀 수 없다)
+func CanRawLiquid(text string) bool {
+	return !strings.Contains(text, "endraw")
+}
+
+// LiquidRaw Liquid 문법이 있으면 {% raw %}...{% endraw %} 로 감싸 Jekyll 이 실행하지 않게 합니다.
+// 감쌀 수 없는 경우는 그대로 두며, 글 머리말에 render_with_liquid: false 를 넣어야 한다.
+func LiquidRaw(text string) string {
+	if !ContainsLiquid(text) || !CanRawLiquid(text) {
+		return text
+	}
+	return "{% raw %}" + text + "{% endraw %}"
+}
@@ -20,8 +20,8 @@ func Strikethrough(text string) string {
 	return fmt.Sprintf("~~%s~~", text)
 }
 
-func Equation(text string) string {
-	return fmt.Sprintf("$%s$", text)
+func Equation(open, close, text string) string {
+	return fmt.Sprintf("%s%s%s", open, text, close)
 }
 
 func Underline(text string) string {
@@ -2,6 +2,7 @@ package markdown
 
 import (
 	"fmt"
+	"html"
 	"strings"
 )
 
@@ -29,21 +30,50 @@ func Code(indent, lang, text string) string {
 	return fmt.Sprintf("%s```%s\n%s%s\n%s```\n\n", indent, lang, indent, text, indent)
 }
 
+// ToggleHeader 토글 제목: 제목(앵커 포함)을 summary 에 넣은 details
+func ToggleHeader(indent string, level int, text, anchor, content string) string {
+	summary := fmt.Sprintf("%s<summary><h%d id=\"%s\">%s</h%d></summary>\n\n", indent, level, anchor, text, level)
+	return fmt.Sprintf("\n%s<details markdown=\"1\">\n%s%s%s</details>\n\n", indent, summary, content, indent)
+}
+
 func Divider(indent string) string {
 	return fmt.Sprintf("%s\n---\n\n", indent)
 }
 
-func BulletedList(indent, text string) string {
-	return fmt.Sprintf("%s- %s\n\n", indent, text)
+func BulletedList(indent, text, children string) string {
+	return ListItem(indent, "- ", text, children)
+}
+
+func NumberedList(indent string, number int, text, children string) string {
+	return ListItem(indent, fmt.Sprintf("%d. ", number), text, children)
 }
 
-func NumberedList(indent string, number uint8, text string) string {
-	return fmt.Sprintf("%s%d. %s\n\n", indent, number, text)
+// ListItem 목록의 항목 하나를 빈 줄 없이(tight) 만듭니다.
+// 여러 줄인 텍스트와 하위 블록(children)은 마커 다음 글자 위치에 맞춰 들여써야 CommonMark, kramdown 모두 같은 항목으로 인식한다.
+// 목록이 끝난 뒤의 빈 줄은 호출하는 쪽에서 넣는다.
+func ListItem(indent, marker, text, children string) string {
+	pad := indent + strings.Repeat(" ", len(marker))
+
+	var sb strings.Builder
+	sb.WriteString(indent + marker + strings.ReplaceAll(text, "\n", "\n"+pad) + "\n")
+	if children = strings.TrimRight(children, "\n"); children != "" {
+		for _, line := range strings.Split(children, "\n") {
+			if line == "" {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString(pad + line + "\n")
+			}
+		}
+	}
+
+	return sb.String()
 }
 
+// Toggle 토글: 텍스트를 summary 에 넣은 details. content 는 details 와 같은 들여쓰기여야 한다.
+// markdown="1": kramdown 이 details 안의 마크다운(목록 등)을 변환하도록 한다.
 func Toggle(indent, text, content string) string {
-	summary := fmt.Sprintf("%s<summary>%s</summary>\n", indent, text)
-	return fmt.Sprintf("%s<details>\n%s%s%s</details>\n\n", indent, summary, content, indent)
+	summary := fmt.Sprintf("%s<summary>%s</summary>\n\n", indent, text)
+	return fmt.Sprintf("%s<details markdown=\"1\">\n%s%s%s</details>\n\n", indent, summary, content, indent)
 }
 
 func Quote(indent, text string) string {
@@ -51,24 +81,162 @@ func Quote(indent, text string) string {
 	return fmt.Sprintf("%s> %s\n\n", indent, text)
 }
 
-func Callout(indent, text string) string {
-	return fmt.Sprintf("%s> 🦖 %s\n\n", indent, text)
+// Callout 아이콘과 본문(하위 블록 포함)을 인용문으로 감싸고, prompt 가 있으면 Chirpy 의 prompt 클래스를 붙입니다.
+func Callout(indent, icon, body, prompt string) string {
+	if icon == "" {
+		icon = "🦖"
+	}
+
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(icon+" "+body, "\n"), "\n") {
+		if line == "" {
+			sb.WriteString(indent + ">\n")
+		} else {
+			sb.WriteString(indent + "> " + line + "\n")
+		}
+	}
+	if prompt != "" {
+		sb.WriteString(fmt.Sprintf("%s{: .prompt-%s }\n", indent, prompt))
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
 }
 
-func Image(indent, imagePath string) string {
-	return fmt.Sprintf("%s![](%s)\n", indent, imagePath)
+type ImageOptions struct {
+	Alt     string // 서식 없는 텍스트 (이스케이프하지 않은)
+	Caption string // 마크다운 서식 포함 (이스케이프한)
+	Width   int
+	Height  int
+	Align   string // left | right
+	Figure  bool   // Chirpy 의 _caption_ 대신 <figure><figcaption> 사용
 }
 
-func ToDo(indent string, text string, checked bool) string {
+func Image(indent, imagePath string, opts ImageOptions) string {
+	if opts.Figure {
+		var attrs string
+		if opts.Width > 0 {
+			attrs += fmt.Sprintf(" width=\"%d\"", opts.Width)
+		}
+		if opts.Height > 0 {
+			attrs += fmt.Sprintf(" height=\"%d\"", opts.Height)
+		}
+		class := ""
+		if opts.Align != "" {
+			class = fmt.Sprintf(" class=\"%s\"", opts.Align)
+		}
+
+		// markdown="span": kramdown 이 figcaption 안의 캡션 서식을 변환하도록 한다.
+		alt := strings.ReplaceAll(Escape(opts.Alt), "\"", "&quot;")
+		figure := fmt.Sprintf("%s<figure%s markdown=\"span\"><img src=\"%s\" alt=\"%s\"%s>", indent, class, imagePath, alt, attrs)
+		if opts.Caption != "" {
+			figure += fmt.Sprintf("<figcaption>%s</figcaption>", opts.Caption)
+		}
+		return figure + "</figure>\n\n"
+	}
+
+	// Chirpy: ![alt](path){: width="640" height="360" .left } 다음 줄의 _caption_ 이 캡션이 된다.
+	var attrs []string
+	if opts.Width > 0 {
+		attrs = append(attrs, fmt.Sprintf("width=\"%d\"", opts.Width))
+	}
+	if opts.Height > 0 {
+		attrs = append(attrs, fmt.Sprintf("height=\"%d\"", opts.Height))
+	}
+	if opts.Align != "" {
+		attrs = append(attrs, "."+opts.Align)
+	}
+
+	image := fmt.Sprintf("%s![%s](%s)", indent, Escape(opts.Alt), imagePath)
+	if len(attrs) > 0 {
+		image += "{: " + strings.Join(attrs, " ") + " }"
+	}
+	image += "\n"
+	if opts.Caption != "" {
+		image += fmt.Sprintf("%s_%s_\n", indent, opts.Caption)
+	}
+
+	// 다음 블록과 한 문단으로 합쳐지지 않도록 빈 줄을 둔다.
+	return image + "\n"
+}
+
+func ToDo(indent string, text string, checked bool, children string) string {
 	if checked {
-		return fmt.Sprintf("%s- [x] %s\n", indent, text)
+		return ListItem(indent, "- ", "[x] "+text, children)
 	}
-	return fmt.Sprintf("%s- [ ] %s\n", indent, text)
+	return ListItem(indent, "- ", "[ ] "+text, children)
 }
 
 func Bookmark(indent, url, title string) string {
 	if title == "" {
 		title = url
 	}
-	return fmt.Sprintf("%s> 🔗 [%s](%s)\n", indent, title, url)
+	return fmt.Sprintf("%s> 🔗 [%s](%s)\n", indent, Escape(title), url)
+}
+
+func PageLink(indent, icon, title, url string) string {
+	if icon == "" {
+		icon = "📄"
+	}
+	return fmt.Sprintf("%s> %s [%s](%s)\n\n", indent, icon, title, url)
+}
+
+// Breadcrumb 상위 글 링크들을 " / " 로 이어 붙입니다.
+func Breadcrumb(indent string, links []string) string {
+	if len(links) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s%s\n\n", indent, strings.Join(links, " / "))
+}
+
+func BlockEquation(indent, open, close, text string) string {
+	text = strings.ReplaceAll(text, "\n", "\n"+indent)
+	return fmt.Sprintf("%s%s\n%s%s\n%s%s\n\n", indent, open, indent, text, indent, close)
+}
+
+// Include 테마의 Liquid include (예: {% include embed/youtube.html id='...' %})
+func Include(indent, include string) string {
+	return fmt.Sprintf("%s%s\n\n", indent, include)
+}
+
+func Embed(indent, url string) string {
+	return fmt.Sprintf("%s<iframe src=\"%s\" width=\"100%%\" height=\"400\" frameborder=\"0\" loading=\"lazy\" sandbox=\"allow-scripts allow-same-origin allow-popups allow-presentation\" allowfullscreen></iframe>\n\n", indent, html.EscapeString(url))
+}
+
+func PDF(indent, name, url string) string {
+	url = html.EscapeString(url)
+	return fmt.Sprintf("%s<object data=\"%s\" type=\"application/pdf\" width=\"100%%\" height=\"600\"><a href=\"%s\">%s</a></object>\n\n", indent, url, url, html.EscapeString(name))
+}
+
+func FileLink(indent, name, url string) string {
+	return fmt.Sprintf("%s> 📎 [%s](%s)\n\n", indent, Escape(name), url)
+}
+
+// Columns 컬럼 레이아웃. columns 는 각 컬럼의 내용, ratios 는 너비 비율(없으면 0)입니다.
+func Columns(indent string, columns []string, ratios []float64) string {
+	var sb strings.Builder
+
+	sb.WriteString(indent + "<div class=\"notion-columns\">\n")
+	for i, content := range columns {
+		style := ""
+		if ratios[i] > 0 {
+			style = fmt.Sprintf(" style=\"flex-grow: %g\"", ratios[i])
+		}
+		// markdown="1": kramdown 이 div 안의 마크다운을 변환하도록 한다.
+		sb.WriteString(fmt.Sprintf("%s<div class=\"notion-column\"%s markdown=\"1\">\n\n%s%s</div>\n", indent, style, content, indent))
+	}
+	sb.WriteString(indent + "</div>\n\n")
+
+	return sb.String()
+}
+
+// ColumnsCSS 컬럼 레이아웃 CSS 입니다. 좁은 화면에서는 위에서 아래로 쌓는다.
+func ColumnsCSS() string {
+	return `/* 컬럼 레이아웃 */
+.notion-columns { display: flex; gap: 1.5rem; }
+.notion-column { flex: 1 1 0; min-width: 0; }
+@media (max-width: 768px) {
+  .notion-columns { flex-direction: column; }
+}
+`
 }
@@ -0,0 +1,684 @@
+package notion
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiSource 는 Notion 공개 REST API 로부터 데이터를 가져와
+// notion.db 와 동일한 레코드 형식(properties/format/content JSON)으로 변환하는 Source 입니다.
+// 데스크톱 앱이 없는 환경(CI 등)에서 동기화를 실행하기 위해 사용합니다.
+type apiSource struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+
+	mutex  sync.Mutex
+	blocks map[string]apiBlock // children 조회 시 함께 받은 블록 캐시
+}
+
+// apiClient 공개 API 요청에 사용하는 client. 응답이 없는 요청 하나가 동기화 전체를 멈추지 않도록 시간 제한을 둔다.
+var apiClient = &http.Client{Timeout: 30 * time.Second}
+
+// NewAPISource Notion 공개 API 를 읽는 Source 를 생성합니다.
+// baseURL 은 보통 ApiBaseURL 이며, 테스트에서는 httptest 서버 주소를 넘깁니다.
+func NewAPISource(baseURL, apiKey string) Source {
+	return &apiSource{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  apiClient,
+		blocks:  make(map[string]apiBlock),
+	}
+}
+
+func (s *apiSource) Close() error {
+	return nil
+}
+
+type apiError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// do API 를 호출하고 응답을 out 으로 디코딩합니다. rate limit(429) 의 경우 Retry-After 만큼 기다린 뒤 재시도합니다.
+func (s *apiSource) do(method, path string, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, s.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Add("Authorization", "Bearer "+s.apiKey)
+		req.Header.Add("Notion-Version", ApiVersion)
+		if body != nil {
+			req.Header.Add("Content-Type", "application/json")
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < 3 {
+			wait, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			time.Sleep(time.Duration(wait+1) * time.Second)
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			var apiErr apiError
+			_ = json.Unmarshal(data, &apiErr)
+			return fmt.Errorf("notion api %s %s: %d %s (%s)", method, path, resp.StatusCode, apiErr.Message, apiErr.Code)
+		}
+
+		return json.Unmarshal(data, out)
+	}
+}
+
+func (s *apiSource) RootType(rootID string) (string, error) {
+	block, err := s.Block(rootID)
+	if err != nil {
+		return "", err
+	}
+
+	return block.Type, nil
+}
+
+// CollectionID 공개 API 에서는 database ID 가 곧 블록 ID 입니다.
+func (s *apiSource) CollectionID(rootID string) (string, error) {
+	return rootID, nil
+}
+
+func (s *apiSource) CollectionSchema(collectionID string) (string, error) {
+	var database struct {
+		Properties map[string]apiPropertySchema `json:"properties"`
+	}
+	if err := s.do(http.MethodGet, "/databases/"+collectionID, nil, &database); err != nil {
+		return "", err
+	}
+
+	schemaMap := make(map[string]Schema)
+	for name, prop := range database.Properties {
+		schemaMap[apiPropertyID(prop.ID)] = prop.toSchema(name)
+	}
+
+	rawSchema, err := json.Marshal(schemaMap)
+	return string(rawSchema), err
+}
+
+// CollectionViewFormat 공개 API 는 데이터베이스 뷰를 제공하지 않으므로 항상 빈 값을 반환합니다.
+func (s *apiSource) CollectionViewFormat(blockID string) (string, error) {
+	return "", nil
+}
+
+func (s *apiSource) Pages(parentID string) (pages []PageRecord, err error) {
+	query := map[string]interface{}{"page_size": 100}
+
+	for {
+		var result struct {
+			Results    []apiPage `json:"results"`
+			HasMore    bool      `json:"has_more"`
+			NextCursor string    `json:"next_cursor"`
+		}
+		if err = s.do(http.MethodPost, "/databases/"+parentID+"/query", query, &result); err != nil {
+			return nil, err
+		}
+
+		for _, page := range result.Results {
+			if page.Archived || page.InTrash {
+				continue
+			}
+
+			rawProperties, err := json.Marshal(page.toProperties())
+			if err != nil {
+				return nil, err
+			}
+			pages = append(pages, PageRecord{ID: page.ID, Properties: string(rawProperties)})
+		}
+
+		if !result.HasMore {
+			return pages, nil
+		}
+		query["start_cursor"] = result.NextCursor
+	}
+}
+
+func (s *apiSource) Block(blockID string) (Block, error) {
+	s.mutex.Lock()
+	block, ok := s.blocks[blockID]
+	s.mutex.Unlock()
+
+	if !ok {
+		if err := s.do(http.MethodGet, "/blocks/"+blockID, nil, &block); err != nil {
+			return Block{}, err
+		}
+	}
+
+	var childIDs []string
+	// 동기화 블록 사본의 하위 블록은 원본의 것이므로 가져오지 않는다.
+	if block.HasChildren && !(block.Type == "synced_block" && block.Payload.SyncedFrom != nil) {
+		children, err := s.children(blockID)
+		if err != nil {
+			return Block{}, err
+		}
+		for _, child := range children {
+			childIDs = append(childIDs, child.ID)
+		}
+	}
+
+	return block.toBlock(childIDs), nil
+}
+
+func (s *apiSource) UserName(userID string) (string, error) {
+	var user struct {
+		Name string `json:"name"`
+	}
+	if err := s.do(http.MethodGet, "/users/"+userID, nil, &user); err != nil {
+		return "", err
+	}
+
+	return user.Name, nil
+}
+
+// children 블록의 하위 블록을 페이지네이션하며 모두 가져오고 캐시에 저장합니다.
+func (s *apiSource) children(blockID string) (children []apiBlock, err error) {
+	cursor := ""
+
+	for {
+		path := "/blocks/" + blockID + "/children?page_size=100"
+		if cursor != "" {
+			path += "&start_cursor=" + url.QueryEscape(cursor)
+		}
+
+		var result struct {
+			Results    []apiBlock `json:"results"`
+			HasMore    bool       `json:"has_more"`
+			NextCursor string     `json:"next_cursor"`
+		}
+		if err = s.do(http.MethodGet, path, nil, &result); err != nil {
+			return nil, err
+		}
+
+		s.mutex.Lock()
+		for _, child := range result.Results {
+			s.blocks[child.ID] = child
+		}
+		s.mutex.Unlock()
+
+		children = append(children, result.Results...)
+
+		if !result.HasMore {
+			return children, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+//////////////////////////////////
+// API 응답 → notion.db 레코드 변환 //
+//////////////////////////////////
+
+type apiRichText struct {
+	Type        string  `json:"type"`
+	PlainText   string  `json:"plain_text"`
+	Href        *string `json:"href"`
+	Annotations struct {
+		Bold          bool   `json:"bold"`
+		Italic        bool   `json:"italic"`
+		Strikethrough bool   `json:"strikethrough"`
+		Underline     bool   `json:"underline"`
+		Code          bool   `json:"code"`
+		Color         string `json:"color"`
+	} `json:"annotations"`
+	Equation *struct {
+		Expression string `json:"expression"`
+	} `json:"equation"`
+	Mention *struct {
+		Type string `json:"type"`
+		Page *struct {
+			ID string `json:"id"`
+		} `json:"page"`
+		User *struct {
+			ID string `json:"id"`
+		} `json:"user"`
+		Date *apiDate `json:"date"`
+	} `json:"mention"`
+}
+
+type apiDate struct {
+	Start string  `json:"start"`
+	End   *string `json:"end"`
+}
+
+// toSegments 공개 API 의 rich text 배열을 notion.db 의 [["text", [["b"], ["a", "url"]]], ...] 형식으로 변환합니다.
+func toSegments(richTexts []apiRichText) [][]interface{} {
+	segments := make([][]interface{}, 0, len(richTexts))
+
+	for _, rt := range richTexts {
+		text := rt.PlainText
+		var formats [][]interface{}
+
+		switch {
+		case rt.Type == "equation" && rt.Equation != nil:
+			text = "⁍"
+			formats = append(formats, []interface{}{"e", rt.Equation.Expression})
+		case rt.Type == "mention" && rt.Mention != nil:
+			switch {
+			case rt.Mention.Page != nil:
+				text = "‣"
+				formats = append(formats, []interface{}{"p", rt.Mention.Page.ID})
+			case rt.Mention.User != nil:
+				text = "‣"
+				formats = append(formats, []interface{}{"u", rt.Mention.User.ID})
+			case rt.Mention.Date != nil:
+				text = "‣"
+				formats = append(formats, []interface{}{"d", rt.Mention.Date.toDateValue()})
+			}
+		}
+
+		if rt.Annotations.Bold {
+			formats = append(formats, []interface{}{"b"})
+		}
+		if rt.Annotations.Italic {
+			formats = append(formats, []interface{}{"i"})
+		}
+		if rt.Annotations.Strikethrough {
+			formats = append(formats, []interface{}{"s"})
+		}
+		if rt.Annotations.Underline {
+			formats = append(formats, []interface{}{"_"})
+		}
+		if rt.Annotations.Code {
+			formats = append(formats, []interface{}{"c"})
+		}
+		if rt.Annotations.Color != "" && rt.Annotations.Color != "default" {
+			formats = append(formats, []interface{}{"h", rt.Annotations.Color})
+		}
+		if rt.Href != nil && rt.Type == "text" {
+			formats = append(formats, []interface{}{"a", *rt.Href})
+		}
+
+		if len(formats) > 0 {
+			segments = append(segments, []interface{}{text, formats})
+		} else {
+			segments = append(segments, []interface{}{text})
+		}
+	}
+
+	return segments
+}
+
+// toDateValue notion.db 의 date 값({"type":"datetime","start_date":"2024-01-15","start_time":"10:30"}) 으로 변환합니다.
+func (d apiDate) toDateValue() map[string]interface{} {
+	value := make(map[string]interface{})
+	hasTime := setDateValue(value, "start", d.Start)
+
+	dateType := "date"
+	if hasTime {
+		dateType = "datetime"
+	}
+	if d.End != nil {
+		setDateValue(value, "end", *d.End)
+		dateType += "range"
+	}
+	value["type"] = dateType
+
+	return value
+}
+
+// setDateValue API 의 ISO 8601 날짜를 {prefix}_date, {prefix}_time 키로 나누어 저장하고, 시간이 포함되었는지 반환합니다.
+func setDateValue(value map[string]interface{}, prefix, raw string) bool {
+	const dateLayout = "2006-01-02"
+
+	if len(raw) <= len(dateLayout) {
+		value[prefix+"_date"] = raw
+		return false
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		value[prefix+"_date"] = raw[:len(dateLayout)]
+		return false
+	}
+	if location, err := time.LoadLocation("Asia/Seoul"); err == nil {
+		t = t.In(location)
+	}
+	value[prefix+"_date"] = t.Format(dateLayout)
+	value[prefix+"_time"] = t.Format("15:04")
+
+	return true
+}
+
+type apiPropertySchema struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Select *struct {
+		Options []apiSelectOption `json:"options"`
+	} `json:"select"`
+	MultiSelect *struct {
+		Options []apiSelectOption `json:"options"`
+	} `json:"multi_select"`
+	Status *struct {
+		Options []apiSelectOption `json:"options"`
+	} `json:"status"`
+}
+
+type apiSelectOption struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// apiPropertyID API 의 property id 는 URL 인코딩되어 있으므로 디코딩해서 사용합니다.
+func apiPropertyID(id string) string {
+	if decoded, err := url.PathUnescape(id); err == nil {
+		return decoded
+	}
+	return id
+}
+
+func (p apiPropertySchema) toSchema(name string) Schema {
+	schema := Schema{Name: name, Type: p.Type}
+	if p.Type == "rich_text" {
+		schema.Type = "text"
+	}
+
+	var options []apiSelectOption
+	switch {
+	case p.Select != nil:
+		options = p.Select.Options
+	case p.MultiSelect != nil:
+		options = p.MultiSelect.Options
+	case p.Status != nil:
+		options = p.Status.Options
+	}
+	for _, option := range options {
+		schema.Options = append(schema.Options, SchemaOption{ID: option.ID, Value: option.Name, Color: option.Color})
+	}
+
+	return schema
+}
+
+type apiPage struct {
+	ID         string                 `json:"id"`
+	Archived   bool                   `json:"archived"`
+	InTrash    bool                   `json:"in_trash"`
+	Properties map[string]apiProperty `json:"properties"`
+}
+
+type apiProperty struct {
+	ID          string            `json:"id"`
+	Type        string            `json:"type"`
+	Title       []apiRichText     `json:"title"`
+	RichText    []apiRichText     `json:"rich_text"`
+	Select      *apiSelectOption  `json:"select"`
+	Status      *apiSelectOption  `json:"status"`
+	MultiSelect []apiSelectOption `json:"multi_select"`
+	Date        *apiDate          `json:"date"`
+	Checkbox    bool              `json:"checkbox"`
+	Number      *float64          `json:"number"`
+	URL         *string           `json:"url"`
+	Email       *string           `json:"email"`
+	PhoneNumber *string           `json:"phone_number"`
+}
+
+// toProperties 페이지 속성을 notion.db 의 properties 형식(map[propertyID][][]interface{}) 으로 변환합니다.
+// 값이 비어있는 속성은 notion.db 와 마찬가지로 생략합니다.
+func (p apiPage) toProperties() map[string][][]interface{} {
+	properties := make(map[string][][]interface{})
+
+	for _, prop := range p.Properties {
+		var value [][]interface{}
+
+		switch prop.Type {
+		case "title":
+			value = toSegments(prop.Title)
+		case "rich_text":
+			value = toSegments(prop.RichText)
+		case "select":
+			if prop.Select != nil {
+				value = [][]interface{}{{prop.Select.Name}}
+			}
+		case "status":
+			if prop.Status != nil {
+				value = [][]interface{}{{prop.Status.Name}}
+			}
+		case "multi_select":
+			if len(prop.MultiSelect) > 0 {
+				names := make([]string, len(prop.MultiSelect))
+				for i, option := range prop.MultiSelect {
+					names[i] = option.Name
+				}
+				value = [][]interface{}{{strings.Join(names, ",")}}
+			}
+		case "date":
+			if prop.Date != nil {
+				value = [][]interface{}{{"‣", [][]interface{}{{"d", prop.Date.toDateValue()}}}}
+			}
+		case "checkbox":
+			checked := "No"
+			if prop.Checkbox {
+				checked = "Yes"
+			}
+			value = [][]interface{}{{checked}}
+		case "number":
+			if prop.Number != nil {
+				value = [][]interface{}{{strconv.FormatFloat(*prop.Number, 'f', -1, 64)}}
+			}
+		case "url", "email", "phone_number":
+			for _, v := range []*string{prop.URL, prop.Email, prop.PhoneNumber} {
+				if v != nil && *v != "" {
+					value = [][]interface{}{{*v}}
+				}
+			}
+		}
+
+		if len(value) > 0 {
+			properties[apiPropertyID(prop.ID)] = value
+		}
+	}
+
+	return properties
+}
+
+type apiBlock struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	HasChildren    bool      `json:"has_children"`
+	CreatedTime    time.Time `json:"created_time"`
+	LastEditedTime time.Time `json:"last_edited_time"`
+	Payload        apiBlockPayload
+}
+
+// apiBlockPayload 블록 type 이름의 키 아래에 있는 type 별 데이터입니다. (예: "paragraph": {...})
+type apiBlockPayload struct {
+	RichText        []apiRichText   `json:"rich_text"`
+	Caption         []apiRichText   `json:"caption"`
+	Checked         bool            `json:"checked"`
+	Language        string          `json:"language"`
+	Title           string          `json:"title"`
+	URL             string          `json:"url"`
+	Expression      string          `json:"expression"`
+	Cells           [][]apiRichText `json:"cells"`
+	TableWidth      int             `json:"table_width"`
+	HasColumnHeader bool            `json:"has_column_header"`
+	HasRowHeader    bool            `json:"has_row_header"`
+	IsToggleable    bool            `json:"is_toggleable"`
+	Color           string          `json:"color"`
+	FileType        string          `json:"type"` // 파일 블록(image, video, audio, file, pdf)의 file | external
+	File            *apiFile        `json:"file"`
+	External        *apiFile        `json:"external"`
+	Name            string          `json:"name"`
+	PageID          string          `json:"page_id"`     // link_to_page
+	DatabaseID      string          `json:"database_id"` // link_to_page
+	SyncedFrom      *struct {
+		BlockID string `json:"block_id"`
+	} `json:"synced_from"`
+	Icon *struct {
+		Type  string `json:"type"`
+		Emoji string `json:"emoji"`
+	} `json:"icon"`
+}
+
+type apiFile struct {
+	URL string `json:"url"`
+}
+
+func (b *apiBlock) UnmarshalJSON(data []byte) error {
+	type plain apiBlock
+	if err := json.Unmarshal(data, (*plain)(b)); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if payload, ok := raw[b.Type]; ok {
+		return json.Unmarshal(payload, &b.Payload)
+	}
+
+	return nil
+}
+
+// apiBlockTypes 공개 API 블록 type → notion.db 블록 type
+var apiBlockTypes = map[string]string{
+	"paragraph":          "text",
+	"heading_1":          "header",
+	"heading_2":          "sub_header",
+	"heading_3":          "sub_sub_header",
+	"bulleted_list_item": "bulleted_list",
+	"numbered_list_item": "numbered_list",
+	"child_page":         "page",
+	"child_database":     "collection_view",
+	"synced_block":       "transclusion_container",
+	"link_to_page":       "alias",
+}
+
+func tableColumnID(i int) string {
+	return fmt.Sprintf("col%d", i)
+}
+
+func (b apiBlock) toBlock(childIDs []string) Block {
+	block := Block{ID: b.ID, Type: b.Type}
+	if !b.CreatedTime.IsZero() {
+		block.CreatedTime = b.CreatedTime.UnixMilli()
+	}
+	if !b.LastEditedTime.IsZero() {
+		block.LastEditedTime = b.LastEditedTime.UnixMilli()
+	}
+	if t, ok := apiBlockTypes[b.Type]; ok {
+		block.Type = t
+	}
+
+	properties := make(map[string]interface{})
+	format := make(map[string]interface{})
+
+	if b.Payload.RichText != nil {
+		properties["title"] = toSegments(b.Payload.RichText)
+	}
+	if len(b.Payload.Caption) > 0 {
+		properties["caption"] = toSegments(b.Payload.Caption)
+	}
+
+	switch b.Type {
+	case "child_page", "child_database":
+		properties["title"] = [][]interface{}{{b.Payload.Title}}
+	case "to_do":
+		checked := "No"
+		if b.Payload.Checked {
+			checked = "Yes"
+		}
+		properties["checked"] = [][]interface{}{{checked}}
+	case "code":
+		properties["language"] = [][]interface{}{{b.Payload.Language}}
+	case "equation":
+		properties["title"] = [][]interface{}{{b.Payload.Expression}}
+	case "image", "video", "audio", "file", "pdf", "embed":
+		// notion.db 와 동일하게 원본 주소를 source 에 둔다. (노션에 올린 파일은 만료되는 주소이므로 다운로드 시 다시 조회)
+		for _, file := range []*apiFile{b.Payload.External, b.Payload.File, {URL: b.Payload.URL}} {
+			if file != nil && file.URL != "" {
+				properties["source"] = [][]interface{}{{file.URL}}
+				break
+			}
+		}
+		if b.Payload.Name != "" {
+			properties["title"] = [][]interface{}{{b.Payload.Name}}
+		}
+	case "bookmark":
+		properties["link"] = [][]interface{}{{b.Payload.URL}}
+	case "callout":
+		if b.Payload.Icon != nil && b.Payload.Icon.Type == "emoji" {
+			format["page_icon"] = b.Payload.Icon.Emoji
+		}
+	case "table":
+		columnOrder := make([]string, b.Payload.TableWidth)
+		for i := range columnOrder {
+			columnOrder[i] = tableColumnID(i)
+		}
+		format["table_block_column_order"] = columnOrder
+		format["table_block_column_header"] = b.Payload.HasColumnHeader
+		format["table_block_row_header"] = b.Payload.HasRowHeader
+	case "table_row":
+		for i, cell := range b.Payload.Cells {
+			properties[tableColumnID(i)] = toSegments(cell)
+		}
+	case "link_to_page":
+		target := b.Payload.PageID
+		if target == "" {
+			target = b.Payload.DatabaseID
+		}
+		format["alias_pointer"] = map[string]string{"id": target, "table": "block"}
+	case "synced_block":
+		if b.Payload.SyncedFrom != nil {
+			// notion.db 와 동일하게, 사본은 하위 블록 없이 원본을 가리킨다.
+			block.Type = "transclusion_reference"
+			format["transclusion_reference_pointer"] = map[string]string{"id": b.Payload.SyncedFrom.BlockID, "table": "block"}
+			childIDs = nil
+		}
+	}
+
+	if b.Payload.IsToggleable {
+		format["toggleable"] = true
+	}
+	if b.Payload.Color != "" && b.Payload.Color != "default" {
+		format["block_color"] = b.Payload.Color
+	}
+
+	if len(properties) > 0 {
+		rawProperties, _ := json.Marshal(properties)
+		block.Properties = sql.NullString{String: string(rawProperties), Valid: true}
+	}
+	if len(format) > 0 {
+		rawFormat, _ := json.Marshal(format)
+		block.Format = sql.NullString{String: string(rawFormat), Valid: true}
+	}
+	if len(childIDs) > 0 {
+		rawContent, _ := json.Marshal(childIDs)
+		block.Content = sql.NullString{String: string(rawContent), Valid: true}
+	}
+
+	return block
+}
@@ -0,0 +1,132 @@
+package notion
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDatabaseID = "806a2a5d-dce8-4729-916a-387f939bc82b"
+	testPageID     = "1eafdee6-189c-46fe-a0fe-5bff83f07309"
+)
+
+// newTestNotionAPI 는 공개 API 의 일부(blocks, databases)를 흉내내는 httptest 서버를 생성합니다.
+func newTestNotionAPI(t *testing.T, routes map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret_test", r.Header.Get("Authorization"))
+		assert.Equal(t, ApiVersion, r.Header.Get("Notion-Version"))
+
+		body, ok := routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"object":"error","status":404,"code":"object_not_found","message":"not found"}`))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func testNotionRoutes() map[string]string {
+	return map[string]string{
+		"GET /blocks/" + testDatabaseID: `{"object":"block","id":"` + testDatabaseID + `","type":"child_database","has_children":false,"child_database":{"title":"Blog"}}`,
+		"GET /databases/" + testDatabaseID: `{"object":"database","id":"` + testDatabaseID + `","properties":{
+			"Title":{"id":"title","type":"title","title":{}},
+			"Status":{"id":"%3AUPp","type":"status","status":{"options":[{"id":"1","name":"Published","color":"green"}]}},
+			"Path":{"id":"pa%7Dh","type":"rich_text","rich_text":{}},
+			"Tags":{"id":"tags","type":"multi_select","multi_select":{"options":[]}},
+			"Published":{"id":"date","type":"date","date":{}}
+		}}`,
+		"POST /databases/" + testDatabaseID + "/query": `{"object":"list","has_more":false,"results":[{"object":"page","id":"` + testPageID + `","archived":false,"properties":{
+			"Title":{"id":"title","type":"title","title":[{"type":"text","plain_text":"API 로 가져온 글","annotations":{"color":"default"}}]},
+			"Status":{"id":"%3AUPp","type":"status","status":{"name":"Published"}},
+			"Path":{"id":"pa%7Dh","type":"rich_text","rich_text":[{"type":"text","plain_text":"fromapi","annotations":{"color":"default"}}]},
+			"Tags":{"id":"tags","type":"multi_select","multi_select":[{"name":"Go"},{"name":"CI"}]},
+			"Published":{"id":"date","type":"date","date":{"start":"2024-01-15T10:30:00.000+09:00","end":null}}
+		}}]}`,
+		"GET /blocks/" + testPageID: `{"object":"block","id":"` + testPageID + `","type":"child_page","has_children":true,"child_page":{"title":"API 로 가져온 글"}}`,
+		"GET /blocks/" + testPageID + "/children": `{"object":"list","has_more":false,"results":[
+			{"object":"block","id":"b1","type":"heading_1","has_children":false,"heading_1":{"rich_text":[{"type":"text","plain_text":"Intro","annotations":{"color":"default"}}]}},
+			{"object":"block","id":"b2","type":"paragraph","has_children":false,"paragraph":{"rich_text":[
+				{"type":"text","plain_text":"Hello ","annotations":{"color":"default"}},
+				{"type":"text","plain_text":"world","href":"https://example.com","annotations":{"bold":true,"color":"default"}}
+			]}},
+			{"object":"block","id":"b3","type":"code","has_children":false,"code":{"language":"go","rich_text":[{"type":"text","plain_text":"fmt.Println()","annotations":{"color":"default"}}]}}
+		]}`,
+	}
+}
+
+func TestAPISourceCollectionSchemaAndPages(t *testing.T) {
+	// Arrange
+	server := newTestNotionAPI(t, testNotionRoutes())
+	src := NewAPISource(server.URL, "secret_test")
+
+	// Act
+	rawSchema, err := src.CollectionSchema(testDatabaseID)
+	require.NoError(t, err)
+	records, err := src.Pages(testDatabaseID)
+	require.NoError(t, err)
+
+	var schema map[string]Schema
+	require.NoError(t, json.Unmarshal([]byte(rawSchema), &schema))
+	page := Page{ID: records[0].ID}
+	parsePageProperties(&page, records[0].Properties, schema)
+
+	// Assert
+	assert.Equal(t, "Status", schema[":UPp"].Name)
+	assert.Equal(t, "text", schema["pa}h"].Type)
+	assert.Equal(t, "API 로 가져온 글", page.Title)
+	assert.Equal(t, "Published", page.Status)
+	assert.Equal(t, "fromapi", page.Path)
+	assert.Equal(t, []string{"Go", "CI"}, page.Tags)
+	assert.Equal(t, "2024-01-15 10:30", page.Published.Format("2006-01-02 15:04"))
+}
+
+func TestAPISourceHandleCollectionView(t *testing.T) {
+	// Arrange
+	server := newTestNotionAPI(t, testNotionRoutes())
+	postDir := t.TempDir()
+	Init("secret_test", NewAPISource(server.URL, "secret_test"))
+
+	// Act
+	report, err := HandleRoot(Root{ID: testDatabaseID, PostDir: postDir, ImgDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.Empty(t, report.Failures)
+
+	// Assert
+	output, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-fromapi.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "title: API 로 가져온 글")
+	assert.Contains(t, string(output), `<h1 id="intro">Intro</h1>`)
+	assert.Contains(t, string(output), "Hello [**world**](https://example.com)")
+	assert.Contains(t, string(output), "```go\nfmt.Println()\n```")
+}
+
+func TestGetFileURLReportsAPIErrors(t *testing.T) {
+	// Arrange
+	server := newTestNotionAPI(t, map[string]string{
+		"GET /blocks/image": `{"object":"block","id":"image","type":"image","image":{"type":"file","file":{"url":"https://files.example.com/a.png"}}}`,
+	})
+	baseURL, apiKey := ApiBaseURL, ApiKey
+	ApiBaseURL, ApiKey = server.URL, "secret_test"
+	t.Cleanup(func() { ApiBaseURL, ApiKey = baseURL, apiKey })
+
+	// Act
+	url, err := getFileURL("image")
+	_, missingErr := getFileURL("missing")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://files.example.com/a.png", url)
+	require.Error(t, missingErr)
+	assert.Contains(t, missingErr.Error(), "404 not found (object_not_found)")
+}
@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"path/filepath"
+	"path"
 	"strings"
 	"sync"
 
@@ -14,15 +14,17 @@ import (
 )
 
 type Block struct {
-	ID         string
-	Type       string
-	Number     uint8
-	ParsedProp ParsedProp
-	Content    sql.NullString
-	Children   []Block
-	Properties sql.NullString
-	Format     sql.NullString
-	Table      *Table
+	ID             string
+	Type           string
+	Number         int // 번호 목록의 번호 (setNumberedListValue 에서 설정)
+	ParsedProp     ParsedProp
+	Content        sql.NullString
+	Children       []Block
+	Properties     sql.NullString
+	Format         sql.NullString
+	CreatedTime    int64 // unix milliseconds (notion.db 형식)
+	LastEditedTime int64 // unix milliseconds, 바뀐 글만 다시 변환하는 데 사용
+	Table          *Table
 }
 
 type ParsedProp struct {
@@ -30,43 +32,88 @@ type ParsedProp struct {
 	Language string // for code type
 }
 
-func parseChildBlocks(block *Block) {
-	childIDs, err := extractChildIDs(block.Content)
-	utils.CheckError(err)
+func parseChildBlocks(block *Block) error {
+	return parseChildBlocksOf(block, map[string]bool{block.ID: true})
+}
+
+// parseChildBlocksOf 하위 블록들을 재귀적으로 불러옵니다.
+// ancestors 는 현재 경로에서 이미 펼친 블록들로, 동기화 블록이 자기 자신을 참조하는 순환을 막는 데 사용한다.
+func parseChildBlocksOf(block *Block, ancestors map[string]bool) error {
+	contentBlock := *block
+	if block.Type == "transclusion_reference" {
+		// 동기화 블록 사본은 하위 블록이 없고, 원본(transclusion_container)의 하위 블록을 그대로 보여준다.
+		format, err := decodeFormat(block.ID, block.Format.String)
+		if err != nil {
+			return err
+		}
+		originalID := format.TransclusionPointer.ID
+		if originalID == "" || ancestors[originalID] {
+			log.Printf("Warning: skip synced block %s (original: %q)", block.ID, originalID)
+			return nil
+		}
+		ancestors[originalID] = true
+		defer delete(ancestors, originalID)
+
+		if contentBlock, err = getBlockData(originalID); err != nil {
+			return err
+		}
+	}
+
+	childIDs, err := extractChildIDs(contentBlock.Content)
+	if err != nil {
+		return fmt.Errorf("block %s: %w", contentBlock.ID, err)
+	}
 
 	for _, childID := range childIDs {
-		childBlock := getBlockData(childID)
-		parseChildBlocks(&childBlock)
+		if ancestors[childID] {
+			continue
+		}
+
+		childBlock, err := getBlockData(childID)
+		if err != nil {
+			return err
+		}
+		// 하위 페이지의 내용은 해당 페이지 문서에 속하므로 내려가지 않는다.
+		if childBlock.Type != "page" {
+			ancestors[childID] = true
+			err = parseChildBlocksOf(&childBlock, ancestors)
+			delete(ancestors, childID)
+			if err != nil {
+				return err
+			}
+		}
 
 		block.Children = append(block.Children, childBlock)
 	}
 
-	return
+	return nil
 }
 
+// setNumberedListValue 연속된 번호 목록 항목에 번호를 매깁니다. 항목의 하위 블록(문단 등)은 번호를 끊지 않는다.
 func setNumberedListValue(blocks *[]Block) {
-	var currentNumber uint8 = 1
+	currentNumber := 1
 
 	for i := range *blocks {
 		if (*blocks)[i].Type == "numbered_list" {
 			(*blocks)[i].Number = currentNumber
 			currentNumber++
-
-			setNumberedListValue(&((*blocks)[i].Children))
 		} else {
 			currentNumber = 1
 		}
+
+		setNumberedListValue(&((*blocks)[i].Children))
 	}
 }
 
-// extractChildIDs 함수 추가
+// extractChildIDs 블록 content 의 하위 블록 ID 목록
 func extractChildIDs(content sql.NullString) (childIDs []string, err error) {
 	if !content.Valid {
 		return
 	}
 
-	err = json.Unmarshal([]byte(content.String), &childIDs)
-	utils.CheckError(err)
+	if err = json.Unmarshal([]byte(content.String), &childIDs); err != nil {
+		return nil, fmt.Errorf("invalid content: %w", err)
+	}
 
 	return
 }
@@ -81,7 +128,8 @@ type HeaderInfo struct {
 	Level  int
 }
 
-func CollectHeaders(blocks []Block) []HeaderInfo {
+// CollectHeaders 목차를 만들기 위해 제목 블록들을 (하위 블록까지) 모읍니다.
+func CollectHeaders(blocks []Block) ([]HeaderInfo, error) {
 	var headers []HeaderInfo
 	for _, block := range blocks {
 		level := 0
@@ -95,8 +143,12 @@ func CollectHeaders(blocks []Block) []HeaderInfo {
 		}
 
 		if level > 0 {
-			title := ParsePropTitle(block.Properties.String)
-			anchor := utils.SanitizeFileName(title)
+			props, err := decodeProperties(block.ID, block.Properties.String)
+			if err != nil {
+				return nil, err
+			}
+			title := ParseText(props["title"])
+			anchor := utils.SanitizeFileName(props.Plain("title"))
 
 			headers = append(headers, HeaderInfo{
 				Title:  title,
@@ -107,79 +159,182 @@ func CollectHeaders(blocks []Block) []HeaderInfo {
 
 		// 재귀적으로 자식 블록 탐색
 		if len(block.Children) > 0 {
-			headers = append(headers, CollectHeaders(block.Children)...)
+			children, err := CollectHeaders(block.Children)
+			if err != nil {
+				return nil, err
+			}
+			headers = append(headers, children...)
 		}
 	}
-	return headers
+	return headers, nil
 }
 
-func ParseBlock(pageID string, block Block, indentLv int, headers []HeaderInfo, wg *sync.WaitGroup, errCh chan error) string {
+// ParseBlocks 형제 블록들을 변환합니다. 연속된 같은 종류의 목록 항목은 빈 줄 없이 하나의 목록으로 묶는다.
+func ParseBlocks(pageID string, blocks []Block, indentLv int, headers []HeaderInfo, wg *sync.WaitGroup, errCh chan error) (string, error) {
 	var output string
+	for i, block := range blocks {
+		blockOutput, err := ParseBlock(pageID, block, indentLv, headers, wg, errCh)
+		if err != nil {
+			return "", err
+		}
+		output += blockOutput
 
-	if block.Properties.String != "" {
-		block.ParsedProp.Title = ParsePropTitle(block.Properties.String)
-		block.ParsedProp.Language = ParsePropLanguage(block.Properties.String)
+		// 목록이 끝나면 빈 줄로 다음 블록과 구분한다.
+		if kind := listKind(block.Type); kind != "" && (i+1 == len(blocks) || listKind(blocks[i+1].Type) != kind) {
+			output += "\n"
+		}
 	}
+	return output, nil
+}
+
+// listKind 목록 항목 블록의 목록 종류 (글머리 기호와 할 일은 같은 "-" 목록)
+func listKind(blockType string) string {
+	switch blockType {
+	case "bulleted_list", "to_do":
+		return "-"
+	case "numbered_list":
+		return "1."
+	default:
+		return ""
+	}
+}
+
+// parseListChildren 목록 항목의 하위 블록을 변환합니다. (들여쓰기는 markdown.ListItem 에서 마커 너비에 맞춘다)
+// 하위 블록이 목록이 아니면 항목의 텍스트와 이어지지 않도록 빈 줄로 시작한다.
+func parseListChildren(pageID string, block Block, headers []HeaderInfo, wg *sync.WaitGroup, errCh chan error) (string, error) {
+	if len(block.Children) == 0 {
+		return "", nil
+	}
+
+	children, err := ParseBlocks(pageID, block.Children, 0, headers, wg, errCh)
+	if err != nil {
+		return "", err
+	}
+	if listKind(block.Children[0].Type) == "" {
+		children = "\n" + children
+	}
+	return children, nil
+}
+
+// ParseBlock 블록(과 하위 블록)을 마크다운으로 변환합니다.
+// 속성을 읽지 못하거나 파일 주소를 가져오지 못하면 오류를 반환하며, 이 경우 글 전체를 내보내지 않는다.
+func ParseBlock(pageID string, block Block, indentLv int, headers []HeaderInfo, wg *sync.WaitGroup, errCh chan error) (string, error) {
+	var output string
+
+	props, err := decodeProperties(block.ID, block.Properties.String)
+	if err != nil {
+		return "", err
+	}
+	format, err := decodeFormat(block.ID, block.Format.String)
+	if err != nil {
+		return "", err
+	}
+	block.ParsedProp.Title = ParseText(props["title"])
+	mentionedPages, _ := collectMentions(block.Properties.String)
+	for _, mentioned := range mentionedPages {
+		addDependency(pageID, blockDependency+mentioned)
+	}
+	block.ParsedProp.Language = props.Plain("language")
 
 	indent := strings.Repeat("   ", indentLv)
 	text := strings.ReplaceAll(block.ParsedProp.Title, "\n", "\n"+indent)
 
 	anchor := ""
 	if block.Type == "header" || block.Type == "sub_header" || block.Type == "sub_sub_header" {
-		anchor = utils.SanitizeFileName(text)
+		anchor = utils.SanitizeFileName(props.Plain("title"))
+	}
+
+	// 블록 색상: 문단과 인용은 블록 전체에(kramdown 속성), 그 외에는 텍스트에 입힌다.
+	blockColor := format.Color()
+	switch block.Type {
+	case "text", "quote", "callout":
+	default:
+		text = markdown.Color(text, blockColor, currentRoot.colorStyle())
 	}
+	// 목록 항목의 여러 줄 텍스트는 markdown.ListItem 에서 마커 너비만큼 들여쓴다.
+	listText := markdown.Color(block.ParsedProp.Title, blockColor, currentRoot.colorStyle())
+
+	// 하위 블록을 직접 처리하는 블록은 children 에 변환 결과를 담고 block.Children 을 비운다.
+	var children string
 
 	switch block.Type {
-	case "header":
-		output = markdown.Header(indent, text, anchor)
-	case "sub_header":
-		output = markdown.SubHeader(indent, text, anchor)
-	case "sub_sub_header":
-		output = markdown.SubSubHeader(indent, text, anchor)
+	case "header", "sub_header", "sub_sub_header":
+		output, err = parseHeader(pageID, block, indentLv, text, anchor, headers, wg, errCh)
+		block.Children = nil
 	case "text":
 		output = markdown.Text(indent, text)
+		if text != "" {
+			output = withColorAttribute(output, indent, blockColor)
+		}
 	case "code":
-		output = markdown.Code(indent, block.ParsedProp.Language, text)
+		// 코드는 이스케이프하지 않은 원문을 쓴다.
+		code := strings.ReplaceAll(props.Plain("title"), "\n", "\n"+indent)
+		output = markdown.LiquidRaw(markdown.Code(indent, block.ParsedProp.Language, code))
 	case "divider":
 		output = markdown.Divider(indent)
 	case "bulleted_list":
-		output = markdown.BulletedList(indent, text)
+		children, err = parseListChildren(pageID, block, headers, wg, errCh)
+		output = markdown.BulletedList(indent, listText, children)
+		block.Children = nil
 	case "numbered_list":
-		output = markdown.NumberedList(indent, block.Number, text)
+		children, err = parseListChildren(pageID, block, headers, wg, errCh)
+		output = markdown.NumberedList(indent, block.Number, listText, children)
+		block.Children = nil
 	case "toggle":
-		var content string
-		for _, child := range block.Children {
-			content += ParseBlock(pageID, child, indentLv+1, headers, wg, errCh)
-		}
-		output = markdown.Toggle(indent, text, content)
+		// 하위 블록은 토글과 같은 들여쓰기로 넣는다. (details 안에서 4칸 이상 들여쓰면 코드 블록이 된다)
+		children, err = ParseBlocks(pageID, block.Children, indentLv, headers, wg, errCh)
+		output = markdown.Toggle(indent, text, children)
 		block.Children = nil
 	case "quote":
-		output = markdown.Quote(indent, text)
+		output = withColorAttribute(markdown.Quote(indent, text), indent, blockColor)
 	case "callout":
-		output = markdown.Callout(indent, text)
+		// 하위 블록은 콜아웃 본문 안에 넣는다.
+		children, err = ParseBlocks(pageID, block.Children, 0, headers, wg, errCh)
+		body := block.ParsedProp.Title + "\n\n" + children
+		// prompt 가 없는 색상(보라, 회색 등)은 블록 색상 속성으로 남긴다.
+		prompt := currentRoot.calloutPrompt(blockColor)
+		output = markdown.Callout(indent, format.Icon(), body, prompt)
+		if prompt == "" {
+			output = withColorAttribute(output, indent, blockColor)
+		}
+		block.Children = nil
 	case "image":
-		imageFileName := SaveImageIfNotExist(pageID, block.ID, wg, errCh)
-		output = markdown.Image(indent, filepath.Join("/assets/pages", pageID, imageFileName))
+		var imageFileName string
+		imageFileName, err = SaveImageIfNotExist(pageID, block.ID, wg, errCh)
+		output = markdown.Image(indent, path.Join(currentRoot.imageURL(), pageID, imageFileName), parseImageOptions(block))
 	case "to_do":
-		output = markdown.ToDo(indent, text, ParseChecked(block.Properties.String))
+		children, err = parseListChildren(pageID, block, headers, wg, errCh)
+		output = markdown.ToDo(indent, listText, props.Checkbox("checked"), children)
+		block.Children = nil
 	case "table":
-		output = createTableMarkdown(&block, block.Children)
+		output, err = createTableMarkdown(indent, &block, block.Children)
 		block.Children = nil
 	case "column_list":
-		var content string
+		var columns []string
+		var ratios []float64
 		for _, child := range block.Children {
 			// 컬럼 리스트의 자식(컬럼)은 들여쓰기를 추가하지 않음
-			content += ParseBlock(pageID, child, indentLv, headers, wg, errCh)
+			column, err := ParseBlock(pageID, child, indentLv, headers, wg, errCh)
+			if err != nil {
+				return "", err
+			}
+			childFormat, _ := decodeFormat(child.ID, child.Format.String) // 잘못된 format 은 위의 ParseBlock 에서 알린다.
+			columns = append(columns, column)
+			ratios = append(ratios, childFormat.ColumnRatio)
+		}
+		if currentRoot.FlattenColumns {
+			output = strings.Join(columns, "")
+		} else {
+			output = markdown.Columns(indent, columns, ratios)
 		}
-		output = content
 		block.Children = nil // 자식 블록은 이미 처리되었으므로 nil로 설정
-	case "column":
-		var content string
-		for _, child := range block.Children {
-			// 컬럼 내의 블록은 들여쓰기를 추가하지 않음
-			content += ParseBlock(pageID, child, indentLv, headers, wg, errCh)
+	case "column", "transclusion_container", "transclusion_reference":
+		if originalID := format.TransclusionPointer.ID; block.Type == "transclusion_reference" && originalID != "" {
+			addDependency(pageID, blockDependency+originalID)
 		}
-		output = content
+		// 동기화 블록(원본과 사본)은 감싸는 블록 없이 하위 블록을 그대로 보여준다.
+		// 컬럼 (또는 동기화 블록) 내의 블록은 들여쓰기를 추가하지 않음
+		output, err = ParseBlocks(pageID, block.Children, indentLv, headers, wg, errCh)
 		block.Children = nil
 	case "table_of_contents":
 		var tocBuilder strings.Builder
@@ -189,125 +344,176 @@ func ParseBlock(pageID string, block Block, indentLv int, headers []HeaderInfo,
 			tocBuilder.WriteString(fmt.Sprintf("%s- [%s](#%s)\n", tocIndent, escapedTitle, h.Anchor))
 		}
 		output = tocBuilder.String()
+	case "page":
+		// 하위 페이지는 별도의 글로 내보내고, 본문에는 링크 카드를 남긴다.
+		output = pageLinkCard(indent, block)
+	case "alias":
+		// 다른 페이지로의 링크(link_to_page)
+		output = aliasLinkCard(pageID, indent, block)
+	case "breadcrumb":
+		output = markdown.Breadcrumb(indent, breadcrumbLinks(pageID))
+	case "collection_view":
+		// 본문에 포함된 인라인 데이터베이스
+		output = createCollectionTableMarkdown(pageID, indent, block)
+	case "equation":
+		open, close := currentRoot.mathDelimiters(true)
+		output = markdown.LiquidRaw(markdown.BlockEquation(indent, open, close, props.Plain("title")))
+	case "video", "audio", "file", "pdf", "embed":
+		output, err = parseMediaBlock(pageID, block, indent, wg, errCh)
 	case "bookmark":
-		url, title, _ := ParseBookmark(block.Properties.String)
+		url, title, _ := ParseBookmark(props)
 		output = markdown.Bookmark(indent, url, title)
 	default:
 		if block.Type != "" {
-			log.Printf("Unsupported block type: %s", block.Type)
+			warnPage(pageID, "unsupported block type %s (block %s)", block.Type, block.ID)
 		}
 		output = ""
 	}
+	if err != nil {
+		return "", err
+	}
 
-	for _, child := range block.Children {
-		output += ParseBlock(pageID, child, indentLv+1, headers, wg, errCh)
+	children, err = ParseBlocks(pageID, block.Children, indentLv+1, headers, wg, errCh)
+	if err != nil {
+		return "", err
 	}
 
-	return output
+	return output + children, nil
 }
 
-func ParsePropLanguage(properties string) (language string) {
-	var props map[string]interface{}
-	if err := json.Unmarshal([]byte(properties), &props); err != nil {
-		panic(err)
+// containsUnsafeLiquid {% raw %} 로 감쌀 수 없는(endraw 가 있는) Liquid 문법이 코드나 수식에 있는지 확인합니다.
+func containsUnsafeLiquid(blocks []Block) bool {
+	unsafe := func(text string) bool {
+		return markdown.ContainsLiquid(text) && !markdown.CanRawLiquid(text)
 	}
 
-	if langValue, ok := props["language"]; ok {
-		langArray := langValue.([]interface{})
-		language = langArray[0].([]interface{})[0].(string)
-	} else {
-		language = ""
-	}
+	for _, block := range blocks {
+		props, _ := decodeProperties(block.ID, block.Properties.String)
+		if (block.Type == "code" || block.Type == "equation") && unsafe(props.Plain("title")) {
+			return true
+		}
 
-	return
-}
+		// 인라인 코드와 인라인 수식: {"title": [["{{ x }}", [["c"]]], ["⁍", [["e", "..."]]]]}
+		for _, segment := range props["title"] {
+			if _, ok := segment.Annotation("c"); ok && unsafe(segment.Text) {
+				return true
+			}
+			if equation, ok := segment.Annotation("e"); ok && unsafe(equation.Value) {
+				return true
+			}
+		}
 
-func ParsePropTitle(properties string) (text string) {
-	var props map[string]interface{}
-	if err := json.Unmarshal([]byte(properties), &props); err != nil {
-		panic(err)
+		if containsUnsafeLiquid(block.Children) {
+			return true
+		}
 	}
+	return false
+}
 
-	text = ParseText(props["title"])
+// ParsePropTitle properties 의 title 속성을 마크다운으로 변환합니다.
+func ParsePropTitle(blockID, properties string) (string, error) {
+	props, err := decodeProperties(blockID, properties)
+	if err != nil {
+		return "", err
+	}
 
-	return
+	return ParseText(props["title"]), nil
 }
 
-func ParseText(text interface{}) (parsedText string) {
-	defer func() {
-		if err := recover(); err != nil {
-			fmt.Println("패닉 복구:", err)
+// ParseText rich text 를 마크다운으로 변환합니다.
+// 예: [["type",[["b"]]], [" "], ["자체가",[["i"]]], [" "], ["하나의",[["_"]]], [" "], ["변환으로",[["s"]]], ...]
+func ParseText(text RichText) (parsedText string) {
+	for _, segment := range text {
+		v := segment.Text
+
+		// 코드가 아닌 일반 텍스트는 마크다운 문법으로 해석되지 않게 이스케이프한다.
+		if _, code := segment.Annotation("c"); !code {
+			v = markdown.Escape(v)
 		}
-	}()
-
-	// INFO - [ ["type",[["b"]]], [" "], ["자체가",[["i"]]], [" "], ["하나의",[["_"]]], [" "], ["변환으로",[["s"]]], ...]
-	for _, value := range text.([]interface{}) {
-		values := value.([]interface{})
-		v := values[0].(string)
-
-		// 길이가 1보다 큰 경우, text 에 대한 추가 형식 변환이 존재한다.
-		if len(values) > 1 {
-			for _, format := range values[1].([]interface{}) {
-				f := format.([]interface{})
-				switch f[0].(string) {
-				case "b":
-					v = markdown.Bold(v)
-				case "i":
-					v = markdown.Italic(v)
-				case "s":
-					v = markdown.Strikethrough(v)
-				case "c":
-					v = markdown.InlineCode(v)
-				case "_":
-					v = markdown.Underline(v)
-				case "e":
-					v = markdown.Equation(f[1].(string)) // [ "⁍", [["e","x+1"]] ]
-				case "a":
-					v = markdown.Link(v, f[1].(string))
-				case "h":
-					// 배경색이므로 무시
-				case "‣":
-					// 페이지 혹은 기타 노션 내부 링크이므로 무시
-				default:
-					//fmt.Printf("Error: Failed to parse properties. (%v) (%s) type\n", properties, f[0].(string))
-				}
+
+		for _, a := range segment.Annotations {
+			switch a.Type {
+			case "b":
+				v = markdown.Bold(v)
+			case "i":
+				v = markdown.Italic(v)
+			case "s":
+				v = markdown.Strikethrough(v)
+			case "c":
+				v = markdown.LiquidRaw(markdown.InlineCode(v))
+			case "_":
+				v = markdown.Underline(v)
+			case "e":
+				open, close := currentRoot.mathDelimiters(false)
+				v = markdown.LiquidRaw(markdown.Equation(open, close, a.Value)) // [ "⁍", [["e","x+1"]] ]
+			case "a":
+				v = markdown.Link(v, a.Value)
+			case "h":
+				v = markdown.Color(v, a.Value, currentRoot.colorStyle()) // [ "text", [["h","red"]] ], [["h","yellow_background"]]
+			case "p":
+				v = pageMention(a.Value) // [ "‣", [["p","<page id>"]] ]
+			case "u":
+				v = userMention(a.Value) // [ "‣", [["u","<user id>"]] ]
+			case "d":
+				v = dateMention(a.Date) // [ "‣", [["d",{"type":"date","start_date":"2024-01-15"}]] ]
 			}
-			parsedText += v
-		} else {
-			parsedText += v
 		}
+		parsedText += v
 	}
 
 	return
 }
 
-func ParseChecked(properties string) bool {
-	var propData map[string]interface{}
-	err := json.Unmarshal([]byte(properties), &propData)
-	utils.CheckError(err)
-
-	checkedData := propData["checked"].([]interface{})
-	checkedValue := checkedData[0].([]interface{})[0].(string)
+// ParseBookmark 북마크 블록의 주소, 제목, 설명
+func ParseBookmark(props Properties) (url string, title string, description string) {
+	return props.Plain("link"), props.Plain("title"), props.Plain("description")
+}
 
-	return checkedValue == "Yes"
+// containsEquation 블록 수식이나 인라인 수식이 하나라도 있는지 확인합니다. (머리말의 math: true 설정에 사용)
+func containsEquation(blocks []Block) bool {
+	for _, block := range blocks {
+		if block.Type == "equation" || containsAnnotation(block.Properties.String, "e") {
+			return true
+		}
+		if containsEquation(block.Children) {
+			return true
+		}
+	}
+	return false
 }
 
-func ParseBookmark(properties string) (url string, title string, description string) {
-	var propData map[string]interface{}
-	if err := json.Unmarshal([]byte(properties), &propData); err != nil {
-		return "", "", ""
+// withColorAttribute 블록 출력의 끝(빈 줄 앞)에 색상 속성을 붙입니다.
+func withColorAttribute(output, indent, color string) string {
+	attribute := markdown.ColorAttribute(indent, color, currentRoot.colorStyle())
+	if attribute == "" {
+		return output
 	}
 
-	if link, ok := propData["link"]; ok {
-		url = link.([]interface{})[0].([]interface{})[0].(string)
+	return strings.TrimSuffix(output, "\n\n") + "\n" + attribute + "\n"
+}
+
+// parseHeader 제목을 변환합니다. 토글 제목의 하위 블록은 details 로 접거나(기본값), 설정에 따라 제목 다음에 펼쳐 넣는다.
+func parseHeader(pageID string, block Block, indentLv int, text, anchor string, headers []HeaderInfo, wg *sync.WaitGroup, errCh chan error) (string, error) {
+	indent := strings.Repeat("   ", indentLv)
+	level := map[string]int{"header": 1, "sub_header": 2, "sub_sub_header": 3}[block.Type]
+
+	// 하위 블록은 제목과 같은 들여쓰기로 넣는다.
+	content, err := ParseBlocks(pageID, block.Children, indentLv, headers, wg, errCh)
+	if err != nil {
+		return "", err
 	}
 
-	if titleArray, ok := propData["title"]; ok {
-		title = titleArray.([]interface{})[0].([]interface{})[0].(string)
+	format, _ := decodeFormat(block.ID, block.Format.String) // 잘못된 format 은 ParseBlock 에서 알린다.
+	if format.Toggleable && !currentRoot.ExpandToggleHeaders {
+		return markdown.ToggleHeader(indent, level, text, anchor, content), nil
 	}
 
-	if descriptionArray, ok := propData["description"]; ok {
-		description = descriptionArray.([]interface{})[0].([]interface{})[0].(string)
+	switch level {
+	case 1:
+		return markdown.Header(indent, text, anchor) + content, nil
+	case 2:
+		return markdown.SubHeader(indent, text, anchor) + content, nil
+	default:
+		return markdown.SubSubHeader(indent, text, anchor) + content, nil
 	}
-	return
 }
@@ -1,7 +1,11 @@
 package notion
 
 import (
+	"database/sql"
+	"github.com/shinychan95/Chan/markdown"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
 )
 
@@ -11,20 +15,362 @@ func TestParsePropTitleItalic(t *testing.T) {
 	expected := "_흥미롭고 유익하고 관심을 끌만한 주제는 무엇이 있을까?_"
 
 	// Act
-	actual := ParsePropTitle(properties)
+	actual, err := ParsePropTitle("block", properties)
 
 	// Assert
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
 func TestParsePropTitleBoldAndBackground(t *testing.T) {
 	// Arrange
+	currentRoot = Root{}
 	properties := "{\"title\":[[\"Notion 에 편하게 글 적고 알아서 블로그에 반영이 된다면?\",[[\"b\"],[\"h\",\"red_background\"]]]]}"
-	expected := "**Notion 에 편하게 글 적고 알아서 블로그에 반영이 된다면?**"
+	expected := "<mark class=\"notion-red-bg\">**Notion 에 편하게 글 적고 알아서 블로그에 반영이 된다면?**</mark>"
 
 	// Act
-	actual := ParsePropTitle(properties)
+	actual, err := ParsePropTitle("block", properties)
 
 	// Assert
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
+
+func TestParsePropTitleEscaping(t *testing.T) {
+	// Arrange
+	currentRoot = Root{}
+	properties := `{"title":[["# 1. *별표* _밑줄_ [링크] <b> {{ site.title }} "],["{{ page.title }}",[["c"]]]]}`
+	expected := "&#35; 1. &#42;별표&#42; &#95;밑줄&#95; &#91;링크&#93; &lt;b&gt; &#123;&#123; site.title &#125;&#125; {% raw %}`{{ page.title }}`{% endraw %}"
+
+	// Act
+	actual, err := ParsePropTitle("block", properties)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestParseBlockCodeWithLiquid(t *testing.T) {
+	// Arrange
+	currentRoot = Root{}
+	code := func(text string) Block {
+		return Block{Type: "code", Properties: sql.NullString{String: `{"title":[["` + text + `"]],"language":[["Liquid"]]}`, Valid: true}}
+	}
+	liquid, endraw := code("{{ post.title }} *"), code("{% raw %}{{ x }}{% endraw %}")
+
+	// Act
+	output := parseBlock(t, liquid, 0)
+
+	// Assert
+	assert.Equal(t, "{% raw %}```Liquid\n{{ post.title }} *\n```\n\n{% endraw %}", output)
+	assert.False(t, containsUnsafeLiquid([]Block{liquid}))
+	assert.True(t, containsUnsafeLiquid([]Block{{Type: "toggle", Children: []Block{endraw}}}))
+}
+
+func TestParsePropTitleMentions(t *testing.T) {
+	// Arrange
+	Init("secret_test", &snapshotSource{
+		blocks: map[string]snapshotBlock{
+			"other": {ID: "other", Type: "page", Properties: []byte(`{"title":[["다른 글"]]}`)},
+		},
+		users: map[string]string{"user-1": "찬영"},
+	})
+	currentRoot = Root{}
+	registerPage(&Page{ID: "posted", Title: "작성한 글", Path: "mentioned"})
+	properties := `{"title":[["‣",[["p","posted"]]],[" "],["‣",[["p","other"]]],[" "],["‣",[["u","user-1"]]],[" "],["‣",[["d",{"type":"daterange","start_date":"2024-01-15","end_date":"2024-01-20"}]]]]}`
+	expected := "[작성한 글](/posts/mentioned/) [다른 글](https://www.notion.so/other) @찬영 2024-01-15 → 2024-01-20"
+
+	// Act
+	actual, err := ParsePropTitle("block", properties)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestParseBlockCalloutWithChildren(t *testing.T) {
+	// Arrange
+	currentRoot = Root{CalloutPrompts: map[string]string{"gray": "tip"}}
+	callout := func(format string) Block {
+		return Block{
+			Type:       "callout",
+			Properties: sql.NullString{String: `{"title":[["주의하세요"]]}`, Valid: true},
+			Format:     sql.NullString{String: format, Valid: format != ""},
+			Children: []Block{
+				{Type: "bulleted_list", Properties: sql.NullString{String: `{"title":[["첫 번째"]]}`, Valid: true}},
+			},
+		}
+	}
+
+	// Act
+	red := parseBlock(t, callout(`{"page_icon":"⚠️","block_color":"red_background"}`), 0)
+	gray := parseBlock(t, callout(`{"block_color":"gray"}`), 1)
+	purple := parseBlock(t, callout(`{"block_color":"purple_background"}`), 0)
+	plain := parseBlock(t, callout(""), 0)
+
+	// Assert
+	assert.Equal(t, "> ⚠️ 주의하세요\n>\n> - 첫 번째\n{: .prompt-danger }\n\n", red)
+	assert.Equal(t, "   > 🦖 주의하세요\n   >\n   > - 첫 번째\n   {: .prompt-tip }\n\n", gray)
+	assert.Equal(t, "> 🦖 주의하세요\n>\n> - 첫 번째\n{: .notion-purple-bg }\n\n", purple)
+	assert.Equal(t, "> 🦖 주의하세요\n>\n> - 첫 번째\n\n", plain)
+}
+
+func TestParseBlockColors(t *testing.T) {
+	// Arrange
+	block := func(blockType, properties, format string) Block {
+		return Block{
+			Type:       blockType,
+			Properties: sql.NullString{String: properties, Valid: true},
+			Format:     sql.NullString{String: format, Valid: format != ""},
+		}
+	}
+	paragraph := block("text", `{"title":[["빨간 "],["글자",[["h","blue"]]]]}`, `{"block_color":"red"}`)
+	item := block("bulleted_list", `{"title":[["노란 배경"]]}`, `{"block_color":"yellow_background"}`)
+
+	// Act
+	currentRoot = Root{}
+	classParagraph := parseBlock(t, paragraph, 0)
+	classItem := parseBlock(t, item, 0)
+	currentRoot = Root{ColorStyle: "inline"}
+	inlineParagraph := parseBlock(t, paragraph, 0)
+	currentRoot = Root{ColorStyle: "none"}
+	noneItem := parseBlock(t, item, 0)
+
+	// Assert
+	assert.Equal(t, "빨간 <span class=\"notion-blue\">글자</span>\n{: .notion-red }\n\n", classParagraph)
+	assert.Equal(t, "- <mark class=\"notion-yellow-bg\">노란 배경</mark>\n", classItem)
+	assert.Equal(t, "빨간 <span style=\"color: #337EA9\">글자</span>\n{: style=\"color: #D44C47\" }\n\n", inlineParagraph)
+	assert.Equal(t, "- 노란 배경\n", noneItem)
+}
+
+func TestParseBlocksLists(t *testing.T) {
+	// Arrange
+	currentRoot = Root{}
+	block := func(blockType, title string, children ...Block) Block {
+		return Block{Type: blockType, Properties: sql.NullString{String: `{"title":[["` + title + `"]]}`, Valid: true}, Children: children}
+	}
+	var numbered []Block
+	for i := 0; i < 300; i++ {
+		numbered = append(numbered, block("numbered_list", "항목"))
+	}
+	numbered[9] = block("numbered_list", "열 번째", block("text", "설명"), block("bulleted_list", "하위"))
+	blocks := append([]Block{
+		block("bulleted_list", "첫 번째", block("numbered_list", "하나"), block("numbered_list", "둘")),
+		{Type: "to_do", Properties: sql.NullString{String: `{"title":[["할 일"]],"checked":[["No"]]}`, Valid: true}},
+	}, numbered...)
+	blocks = append(blocks, block("text", "끝"))
+	setNumberedListValue(&blocks)
+
+	// Act
+	actual, err := ParseBlocks("page", blocks, 0, nil, nil, nil)
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, strings.HasPrefix(actual, "- 첫 번째\n  1. 하나\n  2. 둘\n- [ ] 할 일\n\n1. 항목\n"))
+	assert.Contains(t, actual, "9. 항목\n10. 열 번째\n\n    설명\n\n    - 하위\n11. 항목\n")
+	assert.True(t, strings.HasSuffix(actual, "300. 항목\n\n끝\n\n"))
+}
+
+func TestParseBlockTogglesParseNestedMarkdown(t *testing.T) {
+	// Arrange
+	header := Block{
+		Type:       "sub_header",
+		Properties: sql.NullString{String: `{"title":[["FAQ"]]}`, Valid: true},
+		Format:     sql.NullString{String: `{"toggleable":true}`, Valid: true},
+		Children: []Block{
+			{Type: "text", Properties: sql.NullString{String: `{"title":[["답변"]]}`, Valid: true}},
+			{Type: "bulleted_list", Properties: sql.NullString{String: `{"title":[["항목"]]}`, Valid: true}},
+		},
+	}
+	toggle := Block{
+		Type:       "toggle",
+		Properties: sql.NullString{String: `{"title":[["더 보기"]]}`, Valid: true},
+		Children:   []Block{{Type: "bulleted_list", Properties: sql.NullString{String: `{"title":[["항목"]]}`, Valid: true}}},
+	}
+
+	// Act
+	currentRoot = Root{}
+	collapsed := parseBlock(t, header, 0)
+	nested := parseBlock(t, toggle, 0)
+	currentRoot = Root{ExpandToggleHeaders: true}
+	expanded := parseBlock(t, header, 0)
+
+	// Assert
+	// markdown="1" 이 없으면 kramdown 은 details 안의 목록을 변환하지 않는다.
+	assert.Equal(t, "\n<details markdown=\"1\">\n<summary><h2 id=\"faq\">FAQ</h2></summary>\n\n답변\n\n- 항목\n\n</details>\n\n", collapsed)
+	assert.Equal(t, "<details markdown=\"1\">\n<summary>더 보기</summary>\n\n- 항목\n\n</details>\n\n", nested)
+	assert.Equal(t, "\n<h2 id=\"faq\">FAQ</h2>\n답변\n\n- 항목\n\n", expanded)
+}
+
+func TestImageWithCaptionAndWidth(t *testing.T) {
+	// Arrange
+	image := Block{
+		Type:       "image",
+		Properties: sql.NullString{String: `{"source":[["https://example.com/a.png"]],"caption":[["구조 "],["다이어그램",[["b"]]]]}`, Valid: true},
+		Format:     sql.NullString{String: `{"block_width":640,"block_aspect_ratio":0.5625,"block_alignment":"left"}`, Valid: true},
+	}
+	fullWidth := Block{Type: "image", Format: sql.NullString{String: `{"block_width":1200,"block_full_width":true}`, Valid: true}}
+
+	// Act
+	currentRoot = Root{}
+	chirpy := markdown.Image("", "/assets/pages/p/i.png", parseImageOptions(image))
+	plain := markdown.Image("", "/assets/pages/p/i.png", parseImageOptions(fullWidth))
+	currentRoot = Root{ImageCaptionStyle: "figure"}
+	figure := markdown.Image("", "/assets/pages/p/i.png", parseImageOptions(image))
+
+	// Assert
+	assert.Equal(t, "![구조 다이어그램](/assets/pages/p/i.png){: width=\"640\" height=\"360\" .left }\n_구조 **다이어그램**_\n\n", chirpy)
+	assert.Equal(t, "![](/assets/pages/p/i.png)\n\n", plain)
+	assert.Equal(t, "<figure class=\"left\" markdown=\"span\"><img src=\"/assets/pages/p/i.png\" alt=\"구조 다이어그램\" width=\"640\" height=\"360\"><figcaption>구조 **다이어그램**</figcaption></figure>\n\n", figure)
+}
+
+func TestImageCaptionIsEscaped(t *testing.T) {
+	// Arrange
+	image := Block{
+		Type:       "image",
+		Properties: sql.NullString{String: `{"caption":[["{{ x }} [\"a\"]"]]}`, Valid: true},
+	}
+
+	// Act
+	currentRoot = Root{}
+	chirpy := markdown.Image("", "/assets/pages/p/i.png", parseImageOptions(image))
+	currentRoot = Root{ImageCaptionStyle: "figure"}
+	figure := markdown.Image("", "/assets/pages/p/i.png", parseImageOptions(image))
+
+	// Assert
+	assert.Equal(t, "![&#123;&#123; x &#125;&#125; &#91;\"a\"&#93;](/assets/pages/p/i.png)\n_&#123;&#123; x &#125;&#125; &#91;\"a\"&#93;_\n\n", chirpy)
+	assert.Equal(t, "<figure markdown=\"span\"><img src=\"/assets/pages/p/i.png\" alt=\"&#123;&#123; x &#125;&#125; &#91;&quot;a&quot;&#93;\"><figcaption>&#123;&#123; x &#125;&#125; &#91;\"a\"&#93;</figcaption></figure>\n\n", figure)
+}
+
+func TestBookmarkAndFileLinkAreEscaped(t *testing.T) {
+	// Arrange
+	bookmark := Block{
+		Type:       "bookmark",
+		Properties: sql.NullString{String: `{"link":[["https://example.com"]],"title":[["[링크](x) {{ x }}"]]}`, Valid: true},
+	}
+
+	// Act
+	currentRoot = Root{}
+	output, err := ParseBlock("p", bookmark, 0, nil, nil, nil)
+	file := markdown.FileLink("", "a_b_*c*.pdf", "/assets/pages/p/a.pdf")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "> 🔗 [&#91;링크&#93;(x) &#123;&#123; x &#125;&#125;](https://example.com)\n", output)
+	assert.Equal(t, "> 📎 [a&#95;b&#95;&#42;c&#42;.pdf](/assets/pages/p/a.pdf)\n\n", file)
+}
+
+func TestParseBlockColumnList(t *testing.T) {
+	// Arrange
+	column := func(ratio, title string) Block {
+		return Block{
+			Type:   "column",
+			Format: sql.NullString{String: `{"column_ratio":` + ratio + `}`, Valid: true},
+			Children: []Block{
+				{Type: "text", Properties: sql.NullString{String: `{"title":[["` + title + `"]]}`, Valid: true}},
+			},
+		}
+	}
+	columnList := Block{Type: "column_list", Children: []Block{column("0.25", "왼쪽"), column("0.75", "오른쪽")}}
+
+	// Act
+	currentRoot = Root{}
+	columns := parseBlock(t, columnList, 0)
+	currentRoot = Root{FlattenColumns: true}
+	flattened := parseBlock(t, columnList, 0)
+
+	// Assert
+	assert.Equal(t, "<div class=\"notion-columns\">\n"+
+		"<div class=\"notion-column\" style=\"flex-grow: 0.25\" markdown=\"1\">\n\n왼쪽\n\n</div>\n"+
+		"<div class=\"notion-column\" style=\"flex-grow: 0.75\" markdown=\"1\">\n\n오른쪽\n\n</div>\n"+
+		"</div>\n\n", columns)
+	assert.Equal(t, "왼쪽\n\n오른쪽\n\n", flattened)
+}
+
+func TestCreateTableMarkdown(t *testing.T) {
+	// Arrange
+	table := func(format string) Block {
+		return Block{Type: "table", Format: sql.NullString{String: format, Valid: true}}
+	}
+	row := func(a, b, format string) Block {
+		return Block{
+			Type:       "table_row",
+			Properties: sql.NullString{String: `{"c1":[["` + a + `"]],"c2":[["` + b + `"]]}`, Valid: true},
+			Format:     sql.NullString{String: format, Valid: format != ""},
+		}
+	}
+	render := func(tableBlock Block, rows ...Block) string {
+		output, err := createTableMarkdown("", &tableBlock, rows)
+		require.NoError(t, err)
+		return output
+	}
+	currentRoot = Root{}
+
+	// Act
+	header := table(`{"table_block_column_order":["c1","c2"],"table_block_column_header":true,"table_block_column_format":{"c2":{"color":"blue"}}}`)
+	markdownTable := render(header, row("이름", "값", ""), row("a|b", "1", `{"block_color":"red_background"}`))
+	headerOnly := render(header, row("이름", "값", ""))
+	empty := render(header)
+	rowHeader := table(`{"table_block_column_order":["c1","c2"],"table_block_row_header":true}`)
+	htmlTable := render(rowHeader, row("첫 줄\\n둘째 줄", "값", ""))
+
+	// Assert
+	assert.Equal(t, "| 이름 | <span class=\"notion-blue\">값</span> | \n| --- | --- | \n"+
+		"| <mark class=\"notion-red-bg\">a\\|b</mark> | <mark class=\"notion-red-bg\">1</mark> | \n\n", markdownTable)
+	assert.Equal(t, "| 이름 | <span class=\"notion-blue\">값</span> | \n| --- | --- | \n\n", headerOnly)
+	assert.Equal(t, "", empty)
+	assert.Equal(t, "<table class=\"notion-table\">\n"+
+		"<tr><th scope=\"row\" markdown=\"span\">첫 줄<br/>둘째 줄</th><td markdown=\"span\">값</td></tr>\n"+
+		"</table>\n\n", htmlTable)
+}
+
+func TestInvalidTableAndFormatAreReportedWithBlockID(t *testing.T) {
+	// Arrange
+	currentRoot = Root{}
+	table := Block{
+		ID:       "table",
+		Type:     "table",
+		Format:   sql.NullString{String: `{"table_block_column_order":["c1"]}`, Valid: true},
+		Children: []Block{{ID: "row", Type: "table_row", Properties: sql.NullString{String: `{"c1":"not rich text"}`, Valid: true}}},
+	}
+	rowFormat := Block{
+		ID:       "table",
+		Type:     "table",
+		Format:   sql.NullString{String: `{"table_block_column_order":["c1"]}`, Valid: true},
+		Children: []Block{{ID: "row", Type: "table_row", Format: sql.NullString{String: `{"block_color":1}`, Valid: true}}},
+	}
+	toggle := Block{ID: "header", Type: "sub_header", Format: sql.NullString{String: `{"toggleable":"yes"}`, Valid: true}}
+	header := Block{ID: "header", Type: "header", Properties: sql.NullString{String: `{"title":"제목"}`, Valid: true}}
+
+	// Act
+	_, tableErr := ParseBlock("page", table, 0, nil, nil, nil)
+	_, rowFormatErr := ParseBlock("page", rowFormat, 0, nil, nil, nil)
+	_, formatErr := ParseBlock("page", toggle, 0, nil, nil, nil)
+	_, headersErr := CollectHeaders([]Block{{Type: "toggle", Children: []Block{header}}})
+
+	// Assert
+	var propErr *PropertyError
+	require.ErrorAs(t, tableErr, &propErr)
+	assert.Equal(t, "row", propErr.BlockID)
+	assert.Equal(t, "c1", propErr.Property)
+
+	require.ErrorAs(t, rowFormatErr, &propErr)
+	assert.Equal(t, "row", propErr.BlockID)
+	assert.Equal(t, formatProperty, propErr.Property)
+
+	require.ErrorAs(t, formatErr, &propErr)
+	assert.Equal(t, "header", propErr.BlockID)
+	assert.Equal(t, formatProperty, propErr.Property)
+
+	require.ErrorAs(t, headersErr, &propErr)
+	assert.Equal(t, "header", propErr.BlockID)
+	assert.Equal(t, "title", propErr.Property)
+}
+
+// parseBlock 오류 없이 변환되어야 하는 블록을 변환합니다.
+func parseBlock(t *testing.T, block Block, indentLv int) string {
+	t.Helper()
+	output, err := ParseBlock("page", block, indentLv, nil, nil, nil)
+	require.NoError(t, err)
+	return output
+}
@@ -0,0 +1,130 @@
+package notion
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// pageFolderPattern 이미지 폴더 안의 페이지 ID 폴더 이름 (예: 1519a0a9-70f1-444e-95b4-f6e6fac46131)
+var pageFolderPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// OrphanedImages 루트들의 이미지 폴더에서 manifest 의 어떤 글도 사용하지 않는 이미지 파일과 페이지 폴더를 찾습니다.
+// 폴더 안의 모든 파일을 쓰지 않으면 파일 대신 폴더를 반환합니다.
+// 이미지 폴더 안의 페이지 ID 이름의 폴더만 확인하므로, 테마의 이미지 등 다른 파일은 건드리지 않습니다.
+func OrphanedImages(roots []Root, manifest *Manifest) ([]string, error) {
+	referenced := make(map[string]bool)
+	for _, entry := range manifest.Pages {
+		for _, image := range entry.Images {
+			referenced[filepath.Clean(image)] = true
+		}
+	}
+
+	folders, err := pageFolders(roots)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []string
+	for _, folderPath := range folders {
+		files, err := os.ReadDir(folderPath)
+		if err != nil {
+			return nil, err
+		}
+
+		var unused []string
+		for _, file := range files {
+			filePath := filepath.Join(folderPath, file.Name())
+			if file.IsDir() || !referenced[filePath] {
+				unused = append(unused, filePath)
+			}
+		}
+
+		if len(unused) == len(files) {
+			orphans = append(orphans, folderPath)
+		} else {
+			orphans = append(orphans, unused...)
+		}
+	}
+
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// LatestImageTime 루트들의 이미지 폴더(페이지 ID 폴더)에서 가장 최근에 내려받은 파일의 수정 시각 (파일이 없으면 zero)
+// 저장된 manifest 보다 나중이면 manifest 에 없는 글의 이미지가 있을 수 있다.
+func LatestImageTime(roots []Root) (latest time.Time, err error) {
+	folders, err := pageFolders(roots)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, folderPath := range folders {
+		files, err := os.ReadDir(folderPath)
+		if err != nil {
+			return time.Time{}, err
+		}
+		for _, file := range files {
+			info, err := file.Info()
+			if err != nil {
+				return time.Time{}, err
+			}
+			if info.ModTime().After(latest) {
+				latest = info.ModTime()
+			}
+		}
+	}
+
+	return latest, nil
+}
+
+// pageFolders 루트들의 이미지 폴더 안의 페이지 ID 이름의 폴더 (이미지 폴더가 없으면 건너뜀)
+func pageFolders(roots []Root) (folders []string, err error) {
+	checked := make(map[string]bool)
+	for _, root := range roots {
+		imgDir := filepath.Clean(root.ImgDir)
+		if checked[imgDir] {
+			continue
+		}
+		checked[imgDir] = true
+
+		entries, err := os.ReadDir(imgDir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() && pageFolderPattern.MatchString(entry.Name()) {
+				folders = append(folders, filepath.Join(imgDir, entry.Name()))
+			}
+		}
+	}
+
+	return folders, nil
+}
+
+// RemoveOrphanedImages OrphanedImages 로 찾은 이미지 파일과 페이지 폴더를 지우고, 지운 경로를 반환합니다.
+func RemoveOrphanedImages(roots []Root, manifest *Manifest) ([]string, error) {
+	orphans, err := OrphanedImages(roots, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, orphan := range orphans {
+		if err = os.RemoveAll(orphan); err != nil {
+			log.Printf("Warning: could not remove %s: %v", orphan, err)
+			continue
+		}
+		log.Printf("🗑️ Image removed: %s", orphan)
+		removed = append(removed, orphan)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,60 @@
+package notion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrphanedImages(t *testing.T) {
+	// Arrange
+	imgDir := t.TempDir()
+	const (
+		livePage    = "1519a0a9-70f1-444e-95b4-f6e6fac46131"
+		deletedPage = "2ab4c6d8-1111-4222-8333-944455566677"
+	)
+	write := func(path string) string {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("image"), 0644))
+		return path
+	}
+	used := write(filepath.Join(imgDir, livePage, "a.png"))
+	unused := write(filepath.Join(imgDir, livePage, "b.png"))
+	write(filepath.Join(imgDir, deletedPage, "c.png"))
+	theme := write(filepath.Join(imgDir, "avatar", "me.png")) // 페이지 폴더가 아닌 파일
+
+	roots := []Root{{ID: "root", ImgDir: imgDir}, {ID: "til", ImgDir: imgDir}}
+	manifest := NewManifest()
+	manifest.Pages[livePage] = ManifestPage{Root: "root", File: "_posts/post.md", Images: []string{used}}
+
+	// Act
+	dryRun, dryRunErr := OrphanedImages(roots, manifest)
+	removed, removeErr := RemoveOrphanedImages(roots, manifest)
+
+	// Assert
+	require.NoError(t, dryRunErr)
+	require.NoError(t, removeErr)
+	expected := []string{unused, filepath.Join(imgDir, deletedPage)}
+	assert.ElementsMatch(t, expected, dryRun)
+	assert.ElementsMatch(t, expected, removed)
+
+	assert.FileExists(t, used)
+	assert.FileExists(t, theme)
+	assert.NoFileExists(t, unused)
+	assert.NoDirExists(t, filepath.Join(imgDir, deletedPage))
+}
+
+func TestOrphanedImagesWithoutImageDirectory(t *testing.T) {
+	// Arrange
+	roots := []Root{{ID: "root", ImgDir: filepath.Join(t.TempDir(), "missing")}}
+
+	// Act
+	orphans, err := OrphanedImages(roots, NewManifest())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, orphans)
+}
@@ -1,9 +1,13 @@
 package notion
 
 import (
-	"sync"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
 
-	"github.com/shinychan95/Chan/utils"
+	"github.com/shinychan95/Chan/markdown"
 )
 
 type SchemaOption struct {
@@ -18,32 +22,188 @@ type Schema struct {
 	Options []SchemaOption `json:"options,omitempty"`
 }
 
-func HandleCollectionView(rootId string, wg *sync.WaitGroup, errCh chan error) {
-	rootType := getRootType(rootId)
+// getCollectionViewPages collection view 에서 내보낼 상태의 글들을 가져옵니다.
+// 속성을 읽지 못한 글은 내보내지 않고 failures 로 반환한다.
+func getCollectionViewPages(rootId string) (exportPages []Page, failures []*PageError, err error) {
+	rootType, err := getRootType(rootId)
+	if err != nil {
+		return nil, nil, err
+	}
 	if rootType != "collection_view" {
-		Close() // db close
-		utils.ExecError("block type is not same with exec type")
+		return nil, nil, fmt.Errorf("root %s is %q, not a collection view", rootId, rootType)
 	}
 
 	// block 테이블 내 collection_id 값을 가져온다., 해당 값을 parent_id 로 하는 페이지들을 구한다.
-	collectionId := getCollectionId(rootId)
+	collectionId, err := getCollectionId(rootId)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// collection 테이블 내 해당 collection 의 스키마를 가져온다.
-	collectionSchema := getCollectionSchema(collectionId)
+	collectionSchema, err := getCollectionSchema(collectionId)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// block 테이블 내 해당 collection 을 부모로 하는 페이지들을 가져온다. (template is NULL, alive is 1)
-	pages := getPagesWithProperties(collectionId, collectionSchema)
+	// property 내 Status 가 루트에 설정된 상태(기본값: Published, Archived)인 글들만 내보낸다.
+	pages, failures, err := getPagesWithProperties(collectionId, collectionSchema, currentRoot.shouldExport)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// property 내 Status 가 Drafting 인 글들만 프로세스를 실행한다.
 	for _, page := range pages {
-		if page.Status == "Published" || page.Status == "Archived" {
-			wg.Add(1)
-			go func(page Page) {
-				handlePage(page, wg, errCh)
-				wg.Done()
-			}(page)
+		currentRoot.applyFrontMatter(&page)
+		exportPages = append(exportPages, page)
+	}
+
+	return
+}
+
+// createCollectionTableMarkdown 본문에 포함된 인라인 데이터베이스(collection_view 블록)를 표로 변환합니다.
+// 열은 뷰에 보이는 속성 순서를 따르고, 뷰 정보가 없으면 제목 다음에 나머지 속성을 이름순으로 둡니다.
+func createCollectionTableMarkdown(pageID, indent string, block Block) string {
+	addDependency(pageID, collectionDependency+block.ID)
+
+	collectionID, err := source.CollectionID(block.ID)
+	if err != nil || collectionID == "" {
+		warnPage(pageID, "cannot get collection of inline database %s: %v", block.ID, err)
+		return ""
+	}
+
+	rawSchema, err := source.CollectionSchema(collectionID)
+	if err != nil {
+		warnPage(pageID, "cannot get schema of inline database %s: %v", block.ID, err)
+		return ""
+	}
+	var schema map[string]Schema
+	if err = json.Unmarshal([]byte(rawSchema), &schema); err != nil {
+		warnPage(pageID, "invalid schema of inline database %s: %v", block.ID, err)
+		return ""
+	}
+
+	rawViewFormat, err := source.CollectionViewFormat(block.ID)
+	if err != nil {
+		warnPage(pageID, "cannot get view of inline database %s: %v", block.ID, err)
+	}
+	columns := collectionColumns(schema, rawViewFormat)
+
+	records, err := source.Pages(collectionID)
+	if err != nil {
+		warnPage(pageID, "cannot get rows of inline database %s: %v", block.ID, err)
+		return ""
+	}
+	// notion.db 는 행 순서를 보장하지 않으므로, 동기화할 때마다 같은 글이 되도록 ID 순서로 정렬한다. (collectionState 와 같은 순서)
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+
+	var table strings.Builder
+
+	table.WriteString(indent + "| ")
+	for _, column := range columns {
+		table.WriteString(escapeTableCell(schema[column].Name) + " | ")
+	}
+	table.WriteString("\n" + indent + "| ")
+	for _, column := range columns {
+		if schema[column].Type == "number" {
+			table.WriteString("---: | ")
+		} else {
+			table.WriteString("--- | ")
+		}
+	}
+	table.WriteString("\n")
+
+	for _, record := range records {
+		props, err := decodeProperties(record.ID, record.Properties)
+		if err != nil {
+			warnPage(pageID, "%v", err)
+			continue
+		}
+
+		table.WriteString(indent + "| ")
+		for _, column := range columns {
+			table.WriteString(escapeTableCell(formatCollectionCell(schema[column], props[column])) + " | ")
 		}
+		table.WriteString("\n")
 	}
+	table.WriteString("\n")
+
+	return table.String()
+}
+
+// collectionColumns 표의 열(속성 ID) 순서를 정합니다.
+// 뷰 format 예: {"table_properties": [{"property": "title", "visible": true, "width": 276}, ...]}
+func collectionColumns(schema map[string]Schema, rawViewFormat string) (columns []string) {
+	var format struct {
+		TableProperties []struct {
+			Property string `json:"property"`
+			Visible  *bool  `json:"visible"`
+		} `json:"table_properties"`
+	}
+	if rawViewFormat != "" {
+		if err := json.Unmarshal([]byte(rawViewFormat), &format); err != nil {
+			log.Printf("Warning: invalid view format: %v", err)
+		}
+	}
+
+	for _, property := range format.TableProperties {
+		if _, ok := schema[property.Property]; !ok {
+			continue
+		}
+		if property.Visible == nil || *property.Visible {
+			columns = append(columns, property.Property)
+		}
+	}
+	if len(columns) > 0 {
+		return
+	}
+
+	for id := range schema {
+		columns = append(columns, id)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		a, b := schema[columns[i]], schema[columns[j]]
+		if (a.Type == "title") != (b.Type == "title") {
+			return a.Type == "title"
+		}
+		return a.Name < b.Name
+	})
+
+	return
+}
+
+// formatCollectionCell 속성 타입에 맞게 셀 값을 변환합니다.
+func formatCollectionCell(schema Schema, value RichText) string {
+	if len(value) == 0 {
+		if schema.Type == "checkbox" {
+			return "⬜" // 체크하지 않은 값은 저장되지 않는다.
+		}
+		return ""
+	}
+
+	text := ParseText(value)
+	switch schema.Type {
+	case "select", "multi_select", "status":
+		// 옵션 값은 쉼표로 구분된 문자열로 저장되어 있다. 예: [["Go,CI"]]
+		return strings.Join(strings.Split(text, ","), ", ")
+	case "checkbox":
+		if text == "Yes" {
+			return "✅"
+		}
+		return "⬜"
+	case "url":
+		// 주소는 이스케이프하지 않은 원문을 쓴다.
+		url := value.Plain()
+		if url == "" {
+			return ""
+		}
+		return markdown.Link(markdown.Escape(url), url)
+	default:
+		// title, text, number, date(‣ 날짜 멘션) 등은 서식 그대로
+		return text
+	}
+}
 
-	wg.Wait()
+func escapeTableCell(cell string) string {
+	cell = strings.ReplaceAll(cell, "|", "\\|")
+	return strings.ReplaceAll(cell, "\n", "<br/>")
 }
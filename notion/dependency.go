@@ -0,0 +1,112 @@
+package notion
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 글의 내용에 들어가지만 글(과 본문 블록)의 수정 시각은 바꾸지 않는 블록들.
+// 멘션하거나 링크한 페이지의 제목, 상위 글의 제목(breadcrumb), 동기화 블록의 원본, 인라인 데이터베이스의 행이 바뀌면
+// 글을 다시 변환하도록 글마다 의존하는 블록과 그 상태를 manifest 에 기록한다.
+//
+//	block:<block id>      → 블록의 마지막 수정 시각
+//	collection:<block id> → 인라인 데이터베이스의 스키마, 뷰, 행의 sha256
+const (
+	blockDependency      = "block:"
+	collectionDependency = "collection:"
+)
+
+// 진행 중인 동기화에서 글마다 의존하는 블록 (페이지 ID → 의존 블록)
+var (
+	pageDependencies = make(map[string]map[string]bool)
+	dependencyMutex  sync.Mutex
+)
+
+func resetDependencies() {
+	dependencyMutex.Lock()
+	defer dependencyMutex.Unlock()
+	pageDependencies = make(map[string]map[string]bool)
+}
+
+// addDependency 글을 변환하며 사용한 블록을 기록합니다.
+func addDependency(pageID, key string) {
+	dependencyMutex.Lock()
+	defer dependencyMutex.Unlock()
+
+	if pageDependencies[pageID] == nil {
+		pageDependencies[pageID] = make(map[string]bool)
+	}
+	pageDependencies[pageID][key] = true
+}
+
+// dependencyStates 글이 의존하는 블록들의 현재 상태 (의존하는 블록이 없으면 nil)
+func dependencyStates(pageID string) map[string]string {
+	dependencyMutex.Lock()
+	keys := pageDependencies[pageID]
+	delete(pageDependencies, pageID)
+	dependencyMutex.Unlock()
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	states := make(map[string]string, len(keys))
+	for key := range keys {
+		states[key] = dependencyState(key)
+	}
+	return states
+}
+
+// changedDependencies 이전 동기화 이후 바뀐(혹은 가져올 수 없는) 의존 블록이 있는지 확인합니다.
+func changedDependencies(states map[string]string) bool {
+	for key, state := range states {
+		if state == "" || dependencyState(key) != state {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyState 의존 블록의 현재 상태. 가져오지 못하면 빈 문자열이며, 다음 동기화에서 글을 다시 변환한다.
+func dependencyState(key string) string {
+	switch {
+	case strings.HasPrefix(key, blockDependency):
+		block, err := source.Block(strings.TrimPrefix(key, blockDependency))
+		if err != nil || block.LastEditedTime == 0 {
+			return ""
+		}
+		return strconv.FormatInt(block.LastEditedTime, 10)
+	case strings.HasPrefix(key, collectionDependency):
+		return collectionState(strings.TrimPrefix(key, collectionDependency))
+	default:
+		return ""
+	}
+}
+
+// collectionState 인라인 데이터베이스의 스키마, 뷰, 행(ID 순)의 sha256.
+// 행을 추가하거나 지워도 collection_view 블록의 수정 시각은 바뀌지 않으므로 내용으로 비교한다.
+func collectionState(blockID string) string {
+	collectionID, err := source.CollectionID(blockID)
+	if err != nil || collectionID == "" {
+		return ""
+	}
+	schema, err := source.CollectionSchema(collectionID)
+	if err != nil {
+		return ""
+	}
+	viewFormat, _ := source.CollectionViewFormat(blockID)
+	records, err := source.Pages(collectionID)
+	if err != nil {
+		return ""
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+
+	var sb strings.Builder
+	sb.WriteString(schema + "\n" + viewFormat + "\n")
+	for _, record := range records {
+		sb.WriteString(record.ID + "\t" + record.Properties + "\n")
+	}
+	return contentHash(sb.String())
+}
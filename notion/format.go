@@ -0,0 +1,79 @@
+package notion
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// 노션 블록의 format 은 블록 종류마다 다른 표시 설정이다.
+//   {"block_color": "blue_background", "toggleable": true, "column_ratio": 0.5, "page_icon": "🦖", ...}
+// Format 은 변환에 사용하는 설정만 디코딩하며, 잘못된 형식은 properties 와 같이 블록 ID 를 담은 PropertyError 로 반환한다.
+
+// formatProperty format 을 디코딩하지 못한 경우 PropertyError 의 속성 이름
+const formatProperty = "format"
+
+// Format 디코딩한 블록 format
+type Format struct {
+	BlockColor  string  `json:"block_color"` // 예: "blue", "red_background", "default"
+	Toggleable  bool    `json:"toggleable"`  // 토글 제목
+	ColumnRatio float64 `json:"column_ratio"`
+	PageIcon    string  `json:"page_icon"` // 이모지 혹은 아이콘 이미지 주소
+
+	// 이미지: {"block_width": 640, "block_aspect_ratio": 0.5625, "block_full_width": false, "block_alignment": "left"}
+	BlockWidth       float64 `json:"block_width"`
+	BlockAspectRatio float64 `json:"block_aspect_ratio"`
+	BlockFullWidth   bool    `json:"block_full_width"`
+	BlockPageWidth   bool    `json:"block_page_width"`
+	BlockAlignment   string  `json:"block_alignment"`
+
+	// 표: {"table_block_column_order": ["col1", ...], "table_block_column_header": true,
+	// "table_block_column_format": {"col1": {"width": 120, "color": "blue_background"}}}
+	TableColumnOrder  []string                     `json:"table_block_column_order"`
+	TableColumnHeader bool                         `json:"table_block_column_header"`
+	TableRowHeader    bool                         `json:"table_block_row_header"`
+	TableColumnFormat map[string]TableColumnFormat `json:"table_block_column_format"`
+
+	// 페이지 링크(link_to_page)와 동기화 블록 사본이 가리키는 블록
+	AliasPointer        BlockPointer `json:"alias_pointer"`
+	TransclusionPointer BlockPointer `json:"transclusion_reference_pointer"`
+}
+
+// TableColumnFormat 표 열의 format
+type TableColumnFormat struct {
+	Color string `json:"color"`
+}
+
+// BlockPointer 다른 블록을 가리키는 format 값. 예: {"id": "<block id>", "table": "block", "spaceId": "..."}
+type BlockPointer struct {
+	ID string `json:"id"`
+}
+
+// decodeFormat 블록의 format 을 디코딩합니다. format 이 없으면 빈 Format 을 반환한다.
+func decodeFormat(blockID, rawFormat string) (Format, error) {
+	var format Format
+	if rawFormat == "" {
+		return format, nil
+	}
+
+	if err := json.Unmarshal([]byte(rawFormat), &format); err != nil {
+		return Format{}, &PropertyError{BlockID: blockID, Property: formatProperty, Err: err}
+	}
+
+	return format, nil
+}
+
+// Color 블록의 색상 (기본 색상이면 빈 문자열)
+func (f Format) Color() string {
+	if f.BlockColor == "default" {
+		return ""
+	}
+	return f.BlockColor
+}
+
+// Icon page_icon 이 이모지인 경우 반환합니다. (이미지 아이콘은 무시)
+func (f Format) Icon() string {
+	if strings.HasPrefix(f.PageIcon, "http") || strings.HasPrefix(f.PageIcon, "/") {
+		return ""
+	}
+	return f.PageIcon
+}
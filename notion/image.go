@@ -4,28 +4,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/shinychan95/Chan/utils"
+	"github.com/shinychan95/Chan/markdown"
 )
 
-type ImageBlock struct {
-	Object         string    `json:"object"`
-	ID             string    `json:"id"`
-	CreatedTime    time.Time `json:"created_time"`
-	LastEditedTime time.Time `json:"last_edited_time"`
-	Type           string    `json:"type"`
-	Image          struct {
-		Type     string `json:"type"`
-		File     ImageFile
-		External ImageFile
-	} `json:"image"`
+// FileObject 공개 API 의 파일 블록(image, video, audio, file, pdf) 내용
+type FileObject struct {
+	Type     string    `json:"type"` // file(노션에 올린 파일) | external
+	File     ImageFile `json:"file"`
+	External ImageFile `json:"external"`
 }
 
 type ImageFile struct {
@@ -33,90 +28,121 @@ type ImageFile struct {
 	ExpiryTime time.Time `json:"expiry_time,omitempty"`
 }
 
-func SaveImageIfNotExist(rootID, imageId string, wg *sync.WaitGroup, errCh chan error) string {
-	imageURL, err := getImageURL(imageId)
-	utils.CheckError(err)
+// SaveImageIfNotExist 이미지를 (없는 경우에만) 내려받고 파일 이름을 반환합니다.
+// 이미 내려받은 이미지는 다운로드 주소를 조회하지 않으므로, 다시 변환할 때 네트워크(공개 API)가 필요 없다.
+func SaveImageIfNotExist(pageID, imageId string, wg *sync.WaitGroup, errCh chan error) (string, error) {
+	fileName := fmt.Sprintf("%s.png", imageId)
+	if skipExistingFile(pageID, fileName) {
+		return fileName, nil
+	}
+	if offlineSource() {
+		warnPage(pageID, "image %s is not downloaded (snapshot source does not fetch files)", imageId)
+		return fileName, nil
+	}
 
-	imageFileName := fmt.Sprintf("%s.png", imageId)
-	imagePath := filepath.Join(ImgDir, rootID, imageFileName)
+	imageURL, err := getFileURL(imageId)
+	if err != nil {
+		return "", fmt.Errorf("cannot get image %s: %w", imageId, err)
+	}
 
-	wg.Add(1)
+	saveFile(pageID, fileName, imageURL, wg, errCh)
+	return fileName, nil
+}
+
+// offlineSource 스냅샷으로 변환하는 경우. 스냅샷에는 파일 주소가 없으므로 내려받지 않은 파일은 건너뛴다.
+func offlineSource() bool {
+	_, ok := source.(*snapshotSource)
+	return ok
+}
 
-	if !checkImageExist(imagePath) {
-		go func(url, path string) {
-			defer wg.Done()
-			err = downloadImage(url, path)
-			if err != nil {
-				errCh <- err
-				log.Printf("Error downloading image: %s", err)
-			}
-		}(imageURL, imagePath)
-	} else {
-		wg.Done()
+// skipExistingFile 루트의 ImgDir/페이지 ID 폴더에 이미 내려받은 파일이면 건너뛴 것으로 기록합니다.
+func skipExistingFile(pageID, fileName string) bool {
+	filePath := filepath.Join(currentRoot.ImgDir, pageID, fileName)
+	if !checkImageExist(filePath) {
+		return false
 	}
 
-	return imageFileName
+	reportImage(pageID, filePath, false)
+	return true
+}
+
+// saveFile 파일을 루트의 ImgDir/페이지 ID 폴더에 내려받습니다.
+// 내려받기는 백그라운드에서 진행되며, 실패하면 해당 글의 오류(PageError)로 errCh 에 보낸다.
+func saveFile(pageID, fileName, fileURL string, wg *sync.WaitGroup, errCh chan error) {
+	filePath := filepath.Join(currentRoot.ImgDir, pageID, fileName)
+
+	wg.Add(1)
+	go func(url, path string) {
+		defer wg.Done()
+		err := downloadImage(url, path)
+		if err != nil {
+			log.Printf("Error downloading image: %s", err)
+			errCh <- &PageError{PageID: pageID, Err: err}
+			return
+		}
+		reportImage(pageID, path, true)
+	}(fileURL, filePath)
 }
 
 func downloadImage(url, imagePath string) error {
 	resp, err := http.Get(url)
-	utils.CheckError(err)
+	if err != nil {
+		return err
+	}
 	defer resp.Body.Close()
 
-	dir := filepath.Dir(imagePath)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download %s: %s", filepath.Base(imagePath), resp.Status)
+	}
 
 	// 경로가 존재하지 않으면 폴더 생성
-	if _, err = os.Stat(dir); os.IsNotExist(err) {
-		err = os.MkdirAll(dir, 0755)
-		utils.CheckError(err)
+	if err = os.MkdirAll(filepath.Dir(imagePath), 0755); err != nil {
+		return err
 	}
 
-	// 이미지 파일 저장
+	// 이미지 파일 저장 (중간에 실패하면 다음 동기화에서 다시 받도록 지운다)
 	out, err := os.Create(imagePath)
-	utils.CheckError(err)
+	if err != nil {
+		return err
+	}
 	defer out.Close()
 
-	_, err = io.Copy(out, resp.Body)
-	utils.CheckError(err)
+	if _, err = io.Copy(out, resp.Body); err != nil {
+		os.Remove(imagePath)
+		return err
+	}
 
 	return nil
 }
 
-func getImageURL(blockID string) (string, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://api.notion.com/v1/blocks/%s", blockID), nil)
-	if err != nil {
-		return "", err
-	}
-
-	req.Header.Add("Authorization", "Bearer "+ApiKey)
-	req.Header.Add("Notion-Version", ApiVersion)
+// getFileURL 공개 API 로 파일 블록의 다운로드 주소를 가져옵니다.
+// 노션에 올린 파일의 주소는 일정 시간 뒤 만료되므로 내려받기 직전에 조회합니다.
+func getFileURL(blockID string) (string, error) {
+	// 오류 응답(401, 404 등)의 메시지와 rate limit 재시도, 시간 제한은 공개 API 데이터 소스와 같게 처리한다.
+	api := &apiSource{baseURL: ApiBaseURL, apiKey: ApiKey, client: apiClient}
 
-	resp, err := client.Do(req)
-	if err != nil {
+	// 파일 내용은 블록 type 이름의 키 아래에 있다. 예: {"type": "video", "video": {"type": "file", "file": {...}}}
+	var block map[string]json.RawMessage
+	if err := api.do(http.MethodGet, "/blocks/"+blockID, nil, &block); err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
+	var blockType string
+	if err := json.Unmarshal(block["type"], &blockType); err != nil {
 		return "", err
 	}
-
-	var imageBlock ImageBlock
-	err = json.Unmarshal(body, &imageBlock)
-	if err != nil {
+	var file FileObject
+	if err := json.Unmarshal(block[blockType], &file); err != nil {
 		return "", err
 	}
 
-	if imageBlock.Image.Type == "file" {
-		return imageBlock.Image.File.URL, nil
+	if file.Type == "file" {
+		return file.File.URL, nil
 	}
-	if imageBlock.Image.Type == "external" {
-		return imageBlock.Image.External.URL, nil
+	if file.Type == "external" {
+		return file.External.URL, nil
 	}
 
-	return "", fmt.Errorf("unsupported image type or empty URL for block %s", blockID)
+	return "", fmt.Errorf("unsupported %s type or empty URL for block %s", blockType, blockID)
 }
 
 func checkImageExist(imagePath string) bool {
@@ -127,3 +153,28 @@ func checkImageExist(imagePath string) bool {
 
 	return true
 }
+
+// parseImageOptions 이미지의 캡션(대체 텍스트로도 사용)과 노션에서의 표시 크기, 정렬을 가져옵니다.
+func parseImageOptions(block Block) markdown.ImageOptions {
+	props, _ := decodeProperties(block.ID, block.Properties.String) // 잘못된 속성은 ParseBlock 에서 알린다.
+	opts := markdown.ImageOptions{
+		Alt:     strings.ReplaceAll(props.Plain("caption"), "\n", " "),
+		Caption: strings.ReplaceAll(ParseText(props["caption"]), "\n", " "),
+		Figure:  currentRoot.ImageCaptionStyle == imageCaptionFigure,
+	}
+
+	format, _ := decodeFormat(block.ID, block.Format.String) // 잘못된 format 은 ParseBlock 에서 알린다.
+
+	// 전체 너비/페이지 너비 이미지는 크기를 지정하지 않는다.
+	if format.BlockWidth > 0 && !format.BlockFullWidth && !format.BlockPageWidth {
+		opts.Width = int(math.Round(format.BlockWidth))
+		if format.BlockAspectRatio > 0 {
+			opts.Height = int(math.Round(format.BlockWidth * format.BlockAspectRatio))
+		}
+	}
+	if format.BlockAlignment == "left" || format.BlockAlignment == "right" {
+		opts.Align = format.BlockAlignment
+	}
+
+	return opts
+}
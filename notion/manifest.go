@@ -0,0 +1,264 @@
+package notion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Manifest 마지막 동기화에서 내보낸 글들의 상태입니다.
+// 다음 동기화에서는 마지막 수정 시각이 바뀐 글만 다시 변환하고, 나머지 글은 기존 파일을 그대로 둡니다.
+type Manifest struct {
+	Pages map[string]ManifestPage `json:"pages"` // 페이지 ID → 상태
+}
+
+// ManifestPage 내보낸 글 하나의 상태
+type ManifestPage struct {
+	Root           string   `json:"root"`
+	LastEditedTime int64    `json:"last_edited_time"`           // 페이지와 본문 블록 중 가장 최근 수정 시각 (0 이면 다음 동기화에서 다시 변환)
+	PageEditedTime int64    `json:"page_edited_time,omitempty"` // 페이지 블록의 수정 시각 (본문 블록을 가져오기 전에 비교)
+	Config         string   `json:"config,omitempty"`           // 글을 변환한 루트 설정의 해시 (Root.configHash)
+	Hash           string   `json:"hash"`                       // 글 파일 내용의 sha256
+	File           string   `json:"file"`
+	Images         []string `json:"images,omitempty"` // 글의 이미지(미디어 파일 포함) 경로
+	// Dependencies 글이 의존하는 블록(멘션한 페이지, 동기화 블록 원본, 인라인 데이터베이스 등)과 그 상태
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+}
+
+// NewManifest 빈 manifest 를 만듭니다.
+func NewManifest() *Manifest {
+	return &Manifest{Pages: make(map[string]ManifestPage)}
+}
+
+// LoadManifest 저장된 manifest 를 읽습니다. 파일이 없으면(첫 동기화) 빈 manifest 를 반환합니다.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewManifest(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := NewManifest()
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Pages == nil {
+		manifest.Pages = make(map[string]ManifestPage)
+	}
+
+	return manifest, nil
+}
+
+// Save manifest 를 JSON 으로 저장합니다.
+func (m *Manifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Stale 모든 글을 다시 변환하도록 수정 시각을 비운 사본 (전체 동기화에서는 파일 소유 정보만 사용한다)
+func (m *Manifest) Stale() *Manifest {
+	stale := NewManifest()
+	for pageID, entry := range m.Pages {
+		entry.LastEditedTime = 0
+		stale.Pages[pageID] = entry
+	}
+	return stale
+}
+
+// 진행 중인 동기화의 manifest. previousManifest 가 nil 이면 모든 글을 다시 변환한다.
+// incompleteRoots 는 글(하위 페이지 포함) 목록을 모두 찾지 못한 루트로, 이 루트에서 찾지 못한 글은 지우지 않는다.
+var (
+	previousManifest *Manifest
+	nextManifest     = NewManifest()
+	incompleteRoots  = make(map[string]bool)
+	manifestMutex    sync.Mutex
+)
+
+func resetManifest(previous *Manifest) {
+	manifestMutex.Lock()
+	defer manifestMutex.Unlock()
+	previousManifest = previous
+	nextManifest = NewManifest()
+	incompleteRoots = make(map[string]bool)
+}
+
+// discoveryFailed 루트의 글 목록을 모두 찾지 못했음을 기록합니다. (글이나 하위 페이지의 블록을 가져오지 못한 경우 등)
+// 찾지 못한 글이 Notion 에서 지워진 것인지 알 수 없으므로, 이 루트의 이전 글 파일은 지우지 않는다.
+func discoveryFailed(rootID string) {
+	manifestMutex.Lock()
+	defer manifestMutex.Unlock()
+	incompleteRoots[rootID] = true
+}
+
+// contentHash 글 파일 내용의 sha256
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// latestEditedTime 페이지와 본문 블록 중 가장 최근 수정 시각
+// (하위 페이지의 본문은 별도의 글이므로 포함하지 않는다)
+func latestEditedTime(block Block) int64 {
+	latest := block.LastEditedTime
+	for _, child := range block.Children {
+		edited := child.LastEditedTime
+		if child.Type != "page" {
+			edited = latestEditedTime(child)
+		}
+		if edited > latest {
+			latest = edited
+		}
+	}
+	return latest
+}
+
+// previousPage 이전 동기화에서 내보낸 글의 상태
+func previousPage(pageID string) (ManifestPage, bool) {
+	manifestMutex.Lock()
+	defer manifestMutex.Unlock()
+
+	if previousManifest == nil {
+		return ManifestPage{}, false
+	}
+	entry, ok := previousManifest.Pages[pageID]
+	return entry, ok
+}
+
+// unchangedPage 이전 동기화 이후 바뀌지 않은(수정 시각과 파일 경로, 의존 블록의 상태, 설정이 같고 파일이 남아있는) 글의 상태를 반환합니다.
+func unchangedPage(pageID string, lastEditedTime int64, file string) (ManifestPage, bool) {
+	entry, ok := previousPage(pageID)
+	if !ok || entry.LastEditedTime == 0 || entry.LastEditedTime != lastEditedTime || !unchangedOutput(entry, file) {
+		return ManifestPage{}, false
+	}
+	return entry, true
+}
+
+// unchangedPageBlock 본문 블록을 가져오기 전에, 페이지 블록의 수정 시각으로 바뀌지 않은 글인지 확인합니다.
+// 노션은 본문 블록을 고치면 페이지의 수정 시각도 바꾸므로, 페이지의 수정 시각이 같으면 본문도 같다.
+func unchangedPageBlock(pageID string, pageEditedTime int64, file string) (ManifestPage, bool) {
+	entry, ok := previousPage(pageID)
+	if !ok || entry.LastEditedTime == 0 || entry.PageEditedTime == 0 || entry.PageEditedTime != pageEditedTime || !unchangedOutput(entry, file) {
+		return ManifestPage{}, false
+	}
+	return entry, true
+}
+
+// unchangedOutput 이전 글 파일이 같은 경로에 남아 있고, 변환 결과에 영향을 주는 의존 블록과 루트 설정이 바뀌지 않았는지 확인합니다.
+func unchangedOutput(entry ManifestPage, file string) bool {
+	if entry.File != file || entry.Config != currentRoot.configHash() {
+		return false
+	}
+	if _, err := os.Stat(file); err != nil {
+		return false
+	}
+	return !changedDependencies(entry.Dependencies)
+}
+
+// ownsFile Chan 이 만든 파일인지 확인합니다.
+// 이전 manifest 에 없는 파일(manifest 가 없는 첫 동기화 포함)은 내용이 이번에 만든 글과 같을 때만 Chan 의 파일로 받아들인다.
+func ownsFile(path, hash string) bool {
+	if listedFile(path) {
+		return true
+	}
+
+	content, err := os.ReadFile(path)
+	return err == nil && contentHash(string(content)) == hash
+}
+
+// listedFile 이전 manifest 에 있는(이전 동기화에서 Chan 이 쓴) 파일인지 확인합니다.
+func listedFile(path string) bool {
+	manifestMutex.Lock()
+	defer manifestMutex.Unlock()
+
+	if previousManifest == nil {
+		return false
+	}
+	for _, entry := range previousManifest.Pages {
+		if entry.File == path {
+			return true
+		}
+	}
+	return false
+}
+
+// recordPage 이번 동기화에서 내보낸(혹은 바뀌지 않아 그대로 둔) 글의 상태를 기록합니다.
+func recordPage(pageID string, entry ManifestPage) {
+	manifestMutex.Lock()
+	defer manifestMutex.Unlock()
+	nextManifest.Pages[pageID] = entry
+}
+
+// buildManifest 이번 동기화의 결과로 다음 동기화에 사용할 manifest 를 만들고,
+// 이전 동기화에서 내보냈지만 이번에는 내보내지 않은 글(비공개로 바뀌거나 삭제된 글, 제목이 바뀌어 경로가 달라진 글)의 파일을 지웁니다.
+// 지우는 파일은 이전 manifest 에 있는(Chan 이 만든) 파일뿐이며, 글 목록을 모두 찾지 못한 루트에서는 찾은 글의 이전 경로만 지웁니다.
+// 실패한 글은 이전 파일을 남기고 다음 동기화에서 다시 변환하도록 수정 시각을 비워 둡니다.
+func buildManifest(roots []Root, report *Report) (manifest *Manifest, removed []string) {
+	manifestMutex.Lock()
+	defer manifestMutex.Unlock()
+
+	manifest = nextManifest
+	for _, pr := range report.Pages {
+		entry, ok := manifest.Pages[pr.PageID]
+		if pr.Status == PageExported {
+			entry.Images = pr.Images
+			manifest.Pages[pr.PageID] = entry
+		}
+		if pr.Status != PageFailed {
+			continue
+		}
+
+		if !ok && previousManifest != nil {
+			entry, ok = previousManifest.Pages[pr.PageID]
+		}
+		if ok {
+			entry.LastEditedTime = 0
+			manifest.Pages[pr.PageID] = entry
+		}
+	}
+
+	if previousManifest == nil {
+		return manifest, nil
+	}
+
+	exportedRoots := make(map[string]bool, len(roots))
+	written := make(map[string]bool, len(manifest.Pages))
+	for _, root := range roots {
+		exportedRoots[root.ID] = true
+	}
+	for _, entry := range manifest.Pages {
+		written[entry.File] = true
+	}
+
+	for pageID, entry := range previousManifest.Pages {
+		// 이번에 내보내지 않은 루트의 글과, 글 목록을 모두 찾지 못한 루트에서 이번에 찾지 못한 글은 그대로 둔다.
+		_, found := manifest.Pages[pageID]
+		if !exportedRoots[entry.Root] || (incompleteRoots[entry.Root] && !found) {
+			manifest.Pages[pageID] = entry
+			continue
+		}
+		if written[entry.File] {
+			continue
+		}
+		if err := os.Remove(entry.File); err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: could not remove file %s: %v", entry.File, err)
+			continue
+		}
+		log.Printf("🗑️ Page removed: %s", entry.File)
+		removed = append(removed, entry.File)
+	}
+
+	return manifest, removed
+}
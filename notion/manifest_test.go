@@ -0,0 +1,345 @@
+package notion
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEditedPageTreeSource 모든 블록에 수정 시각이 있는 Docs > Getting Started > Install 페이지 트리
+func newTestEditedPageTreeSource() *snapshotSource {
+	src := newTestPageTreeSource().(*snapshotSource)
+	for id, block := range src.blocks {
+		block.LastEditedTime = 1705314600000
+		src.blocks[id] = block
+	}
+	return src
+}
+
+func TestHandleRootsSkipsUnchangedPages(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	root := Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true}
+	src := newTestEditedPageTreeSource()
+	Init("secret_test", src)
+	first, err := HandleRoots([]Root{root}, nil)
+	require.NoError(t, err)
+
+	docsPath := filepath.Join(postDir, "2024-01-15-docs.md")
+	require.NoError(t, os.WriteFile(docsPath, []byte("변환하지 않은 글"), 0644))
+
+	edited := src.blocks["t3"]
+	edited.Properties = []byte(`{"title":[["새 설치 방법"]]}`)
+	edited.LastEditedTime++
+	src.blocks["t3"] = edited
+	Init("secret_test", src)
+
+	// Act
+	report, err := HandleRoots([]Root{root}, first.Manifest)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Exported())
+	assert.Equal(t, 2, report.Unchanged())
+
+	docs, err := os.ReadFile(docsPath)
+	require.NoError(t, err)
+	assert.Equal(t, "변환하지 않은 글", string(docs))
+
+	install, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs-getting-started-install.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(install), "새 설치 방법")
+
+	require.Len(t, report.Manifest.Pages, 3)
+	assert.Equal(t, first.Manifest.Pages["root"], report.Manifest.Pages["root"])
+	assert.Equal(t, edited.LastEditedTime, report.Manifest.Pages["install"].LastEditedTime)
+	assert.NotEqual(t, first.Manifest.Pages["install"].Hash, report.Manifest.Pages["install"].Hash)
+}
+
+// countingSource 블록을 가져온 횟수를 세는 Source
+type countingSource struct {
+	Source
+	blocks map[string]int
+}
+
+func (s *countingSource) Block(blockID string) (Block, error) {
+	s.blocks[blockID]++
+	return s.Source.Block(blockID)
+}
+
+func TestHandleRootsDoesNotLoadBlocksOfUnchangedPages(t *testing.T) {
+	// Arrange
+	root := Root{ID: "root", PostDir: t.TempDir(), ImgDir: t.TempDir()}
+	src := newTestEditedPageTreeSource()
+	Init("secret_test", src)
+	first, err := HandleRoots([]Root{root}, nil)
+	require.NoError(t, err)
+
+	counting := &countingSource{Source: src, blocks: make(map[string]int)}
+	Init("secret_test", counting)
+
+	// Act
+	report, err := HandleRoots([]Root{root}, first.Manifest)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Unchanged())
+	assert.Zero(t, counting.blocks["t1"]) // 본문 블록은 가져오지 않는다.
+	assert.Equal(t, first.Manifest.Pages["root"], report.Manifest.Pages["root"])
+}
+
+func TestHandleRootsReexportsPagesWhenRenderSettingsChange(t *testing.T) {
+	// Arrange
+	root := Root{ID: "root", PostDir: t.TempDir(), ImgDir: t.TempDir(), IncludeSubPages: true}
+	Init("secret_test", newTestEditedPageTreeSource())
+	first, err := HandleRoots([]Root{root}, nil)
+	require.NoError(t, err)
+
+	// Act
+	root.Statuses = []string{"Published"} // 글 내용과 관계없는 설정
+	unchanged, unchangedErr := HandleRoots([]Root{root}, first.Manifest)
+	root.InlineMath = `\\(...\\)`
+	math, mathErr := HandleRoots([]Root{root}, unchanged.Manifest)
+	root.ImageCaptionStyle = imageCaptionFigure
+	caption, captionErr := HandleRoots([]Root{root}, math.Manifest)
+
+	// Assert
+	require.NoError(t, unchangedErr)
+	assert.Equal(t, 3, unchanged.Unchanged())
+	require.NoError(t, mathErr)
+	assert.Equal(t, 3, math.Exported())
+	require.NoError(t, captionErr)
+	assert.Equal(t, 3, caption.Exported())
+	assert.NotEqual(t, math.Manifest.Pages["root"].Config, caption.Manifest.Pages["root"].Config)
+}
+
+func TestHandleRootsReexportsPagesWithChangedDependencies(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	root := Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true}
+	src := newTestEditedPageTreeSource()
+	mention := src.blocks["t1"]
+	mention.Properties = []byte(`{"title":[["설치: "],["‣",[["p","install"]]]]}`)
+	src.blocks["t1"] = mention
+	inline := src.blocks["t3"]
+	inline.Type, inline.CollectionID = "collection_view", "books"
+	src.blocks["t3"] = inline
+	src.schemas = map[string]json.RawMessage{"books": json.RawMessage(`{"title":{"name":"Name","type":"title"}}`)}
+	src.pages = map[string][]snapshotPage{"books": {{ID: "b1", Properties: json.RawMessage(`{"title":[["Go 언어"]]}`)}}}
+	Init("secret_test", src)
+	first, err := HandleRoots([]Root{root}, nil)
+	require.NoError(t, err)
+
+	// 멘션한 페이지의 제목과 인라인 데이터베이스의 행은 글의 수정 시각을 바꾸지 않는다.
+	src.pages["books"] = append(src.pages["books"], snapshotPage{ID: "b2", Properties: json.RawMessage(`{"title":[["Rust"]]}`)})
+	Init("secret_test", src)
+
+	// Act
+	rowAdded, rowErr := HandleRoots([]Root{root}, first.Manifest)
+
+	install := src.blocks["install"]
+	install.Properties = []byte(`{"title":[["Setup"]]}`)
+	install.LastEditedTime++
+	src.blocks["install"] = install
+	Init("secret_test", src)
+	renamed, renameErr := HandleRoots([]Root{root}, rowAdded.Manifest)
+
+	// Assert
+	require.NoError(t, rowErr)
+	assert.Equal(t, 1, rowAdded.Exported())
+	books, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs-getting-started-setup.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(books), "| Rust |")
+	assert.Contains(t, rowAdded.Manifest.Pages["install"].Dependencies, "collection:t3")
+
+	require.NoError(t, renameErr)
+	assert.Equal(t, 3, renamed.Exported()) // install 과, install 을 멘션한 Docs, 링크 카드가 있는 Getting Started
+	docs, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(docs), "[Setup](/posts/docs-getting-started-setup/)")
+	assert.Contains(t, renamed.Manifest.Pages["root"].Dependencies, "block:install")
+}
+
+func TestHandleRootsRemovesPagesNoLongerExported(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	root := Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true}
+	Init("secret_test", newTestEditedPageTreeSource())
+	first, err := HandleRoots([]Root{root}, nil)
+	require.NoError(t, err)
+
+	// Act
+	root.IncludeSubPages = false
+	report, err := HandleRoots([]Root{root}, first.Manifest)
+
+	// Assert
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(postDir, "2024-01-15-docs-getting-started.md"),
+		filepath.Join(postDir, "2024-01-15-docs-getting-started-install.md"),
+	}, report.Removed)
+
+	files, err := filepath.Glob(filepath.Join(postDir, "*.md"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(postDir, "2024-01-15-docs.md")}, files)
+	assert.Len(t, report.Manifest.Pages, 1)
+}
+
+func TestHandleRootsRetriesFailedPages(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	root := Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true}
+	src := newTestEditedPageTreeSource()
+	Init("secret_test", src)
+	first, err := HandleRoots([]Root{root}, nil)
+	require.NoError(t, err)
+
+	broken := src.blocks["t2"]
+	broken.Properties = []byte(`{"title":"시작하기"}`)
+	broken.LastEditedTime++
+	src.blocks["t2"] = broken
+	Init("secret_test", src)
+
+	// Act
+	report, err := HandleRoots([]Root{root}, first.Manifest)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Failures, 1)
+	assert.Empty(t, report.Removed) // 실패한 글의 이전 파일은 남긴다.
+	assert.FileExists(t, filepath.Join(postDir, "2024-01-15-docs-getting-started.md"))
+	assert.Zero(t, report.Manifest.Pages["child"].LastEditedTime)
+	assert.Equal(t, first.Manifest.Pages["child"].File, report.Manifest.Pages["child"].File)
+}
+
+func TestHandleRootsKeepsPagesUnderFailedSubtree(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	root := Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true}
+	src := newTestEditedPageTreeSource()
+	Init("secret_test", src)
+	first, err := HandleRoots([]Root{root}, nil)
+	require.NoError(t, err)
+
+	broken := src.blocks["child"]
+	broken.Content = json.RawMessage(`{"t2": true}`) // 하위 블록(과 Install 페이지)을 찾을 수 없음
+	src.blocks["child"] = broken
+	Init("secret_test", src)
+
+	// Act
+	report, err := HandleRoots([]Root{root}, first.Manifest)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, "child", report.Failures[0].PageID)
+	assert.Empty(t, report.Removed)
+	assert.FileExists(t, filepath.Join(postDir, "2024-01-15-docs-getting-started.md"))
+	assert.FileExists(t, filepath.Join(postDir, "2024-01-15-docs-getting-started-install.md"))
+	assert.Equal(t, first.Manifest.Pages["install"], report.Manifest.Pages["install"])
+	assert.Len(t, report.Manifest.Pages, 3)
+}
+
+func TestHandleRootsKeepsFilesNotCreatedByChan(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	root := Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true}
+	Init("secret_test", newTestEditedPageTreeSource())
+	first, err := HandleRoots([]Root{root}, nil)
+	require.NoError(t, err)
+
+	handWritten := filepath.Join(postDir, "2023-12-01-hello.md")
+	conflict := filepath.Join(postDir, "2024-01-15-docs-getting-started.md")
+	require.NoError(t, os.WriteFile(handWritten, []byte("직접 쓴 글"), 0644))
+	previous := first.Manifest.Stale()
+	delete(previous.Pages, "child") // manifest 에 없는 파일
+
+	// Act
+	root.IncludeSubPages = false
+	report, err := HandleRoots([]Root{root}, previous)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(postDir, "2024-01-15-docs-getting-started-install.md")}, report.Removed)
+	assert.FileExists(t, handWritten)
+	assert.FileExists(t, conflict)
+	assert.Equal(t, 1, report.Exported()) // 전체 동기화(Stale)이므로 다시 변환
+}
+
+func TestHandleRootsDoesNotOverwriteFilesNotCreatedByChan(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	root := Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true}
+	Init("secret_test", newTestEditedPageTreeSource())
+	first, err := HandleRoots([]Root{root}, nil)
+	require.NoError(t, err)
+
+	conflict := filepath.Join(postDir, "2024-01-15-docs-getting-started.md")
+	require.NoError(t, os.WriteFile(conflict, []byte("직접 쓴 글"), 0644))
+	previous := first.Manifest.Stale()
+	delete(previous.Pages, "child")
+
+	// Act
+	report, err := HandleRoots([]Root{root}, previous)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, "child", report.Failures[0].PageID)
+	assert.Contains(t, report.Failures[0].Error(), "was not created by Chan")
+
+	content, err := os.ReadFile(conflict)
+	require.NoError(t, err)
+	assert.Equal(t, "직접 쓴 글", string(content))
+	assert.NotContains(t, report.Manifest.Pages, "child") // 다음 동기화에서도 Chan 의 파일이 아니다.
+}
+
+func TestHandleRootsWithoutManifestAdoptsOnlyIdenticalFiles(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	root := Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true}
+	Init("secret_test", newTestEditedPageTreeSource())
+	_, err := HandleRoots([]Root{root}, nil)
+	require.NoError(t, err)
+
+	handWritten := filepath.Join(postDir, "2024-01-15-docs-getting-started.md")
+	require.NoError(t, os.WriteFile(handWritten, []byte("직접 쓴 글"), 0644))
+
+	// Act
+	report, err := HandleRoots([]Root{root}, nil) // manifest 가 없는 첫 동기화
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, "child", report.Failures[0].PageID)
+	assert.Contains(t, report.Failures[0].Error(), "was not created by Chan")
+	assert.Equal(t, 2, report.Exported()) // 만들 글과 내용이 같은 파일은 Chan 의 파일로 받아들인다.
+
+	content, err := os.ReadFile(handWritten)
+	require.NoError(t, err)
+	assert.Equal(t, "직접 쓴 글", string(content))
+	assert.NotContains(t, report.Manifest.Pages, "child")
+}
+
+func TestLoadManifest(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "state", "manifest.json")
+	manifest := NewManifest()
+	manifest.Pages["page"] = ManifestPage{Root: "root", LastEditedTime: 1, Hash: "abc", File: "_posts/page.md", Images: []string{"assets/page/image.png"}}
+
+	// Act
+	missing, missingErr := LoadManifest(path)
+	saveErr := manifest.Save(path)
+	loaded, loadErr := LoadManifest(path)
+
+	// Assert
+	require.NoError(t, missingErr)
+	assert.Empty(t, missing.Pages)
+	require.NoError(t, saveErr)
+	require.NoError(t, loadErr)
+	assert.Equal(t, manifest, loaded)
+}
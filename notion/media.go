@@ -0,0 +1,152 @@
+package notion
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/shinychan95/Chan/markdown"
+)
+
+// 미디어 블록(video, audio, file, pdf, embed)은 properties 의 source 에 원본 주소가 있다.
+// 노션에 올린 파일은 "attachment:..." 혹은 노션의 S3 주소이며, 공개 API 로 다운로드 주소를 다시 조회한다.
+//   {"source": [["https://www.youtube.com/watch?v=..."]], "title": [["slides.pdf"]]}
+
+var (
+	youtubeIDPattern = regexp.MustCompile(`(?:youtube\.com/(?:watch\?(?:.*&)?v=|embed/|shorts/|live/)|youtu\.be/)([\w-]{11})`)
+	vimeoIDPattern   = regexp.MustCompile(`vimeo\.com/(?:video/)?(\d+)`)
+)
+
+// videoExtensions 브라우저에서 바로 재생할 수 있는 동영상 파일 확장자
+var videoExtensions = map[string]bool{".mp4": true, ".webm": true, ".mov": true, ".ogv": true, ".m4v": true}
+
+func parseMediaBlock(pageID string, block Block, indent string, wg *sync.WaitGroup, errCh chan error) (string, error) {
+	source, name := parseMediaSource(block.Properties.String)
+	if source == "" {
+		warnPage(pageID, "%s block %s has no source", block.Type, block.ID)
+		return "", nil
+	}
+
+	// 유튜브, 비메오는 테마의 임베드 include 로 넣는다.
+	if block.Type == "video" || block.Type == "embed" {
+		if match := youtubeIDPattern.FindStringSubmatch(source); match != nil {
+			if include := currentRoot.embedInclude("youtube", match[1]); include != "" {
+				return markdown.Include(indent, include), nil
+			}
+			return markdown.Embed(indent, "https://www.youtube.com/embed/"+match[1]), nil
+		}
+		if match := vimeoIDPattern.FindStringSubmatch(source); match != nil {
+			if include := currentRoot.embedInclude("vimeo", match[1]); include != "" {
+				return markdown.Include(indent, include), nil
+			}
+			return markdown.Embed(indent, "https://player.vimeo.com/video/"+match[1]), nil
+		}
+	}
+
+	fileURL, hosted := source, isNotionHosted(source)
+	if name == "" && strings.HasPrefix(source, "attachment:") {
+		name = source[strings.LastIndex(source, ":")+1:]
+	}
+	if hosted {
+		var err error
+		if fileURL, err = saveMediaIfNotExist(pageID, block.ID, name, wg, errCh); err != nil {
+			return "", err
+		}
+	}
+	if name == "" {
+		name = path.Base(fileURL)
+	}
+
+	switch block.Type {
+	case "video":
+		if include := currentRoot.embedInclude("video", fileURL); include != "" && (hosted || videoExtensions[strings.ToLower(path.Ext(fileURL))]) {
+			return markdown.Include(indent, include), nil
+		}
+		return markdown.Embed(indent, fileURL), nil
+	case "audio":
+		if include := currentRoot.embedInclude("audio", fileURL); include != "" {
+			return markdown.Include(indent, include), nil
+		}
+		return markdown.FileLink(indent, name, fileURL), nil
+	case "pdf":
+		return markdown.PDF(indent, name, fileURL), nil
+	case "file":
+		return markdown.FileLink(indent, name, fileURL), nil
+	default:
+		// 그 외 임베드는 https 인 경우 sandbox iframe, 아니면 링크로 남긴다.
+		if strings.HasPrefix(fileURL, "https://") {
+			return markdown.Embed(indent, fileURL), nil
+		}
+		return markdown.Bookmark(indent, fileURL, "") + "\n", nil
+	}
+}
+
+// parseMediaSource properties 에서 원본 주소와 (파일 블록의) 파일 이름을 가져옵니다.
+func parseMediaSource(properties string) (source, name string) {
+	props, _ := decodeProperties("", properties)
+	return props.Plain("source"), props.Plain("title")
+}
+
+// isNotionHosted 노션에 직접 올린 파일인지 확인합니다.
+func isNotionHosted(source string) bool {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return true // attachment:<id>:<name>
+	}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return false
+	}
+	return u.Host == "file.notion.so" ||
+		strings.HasPrefix(u.Path, "/secure.notion-static.com/") ||
+		strings.HasPrefix(u.Host, "prod-files-secure.")
+}
+
+// saveMediaIfNotExist 노션에 올린 파일을 이미지와 같은 페이지 폴더에 (없는 경우에만) 내려받고 블로그에서의 주소를 반환합니다.
+func saveMediaIfNotExist(pageID, blockID, name string, wg *sync.WaitGroup, errCh chan error) (string, error) {
+	ext := path.Ext(name)
+	if fileName, ok := existingMediaFile(pageID, blockID, ext); ok {
+		return path.Join(currentRoot.imageURL(), pageID, fileName), nil
+	}
+	if offlineSource() {
+		warnPage(pageID, "file %s is not downloaded (snapshot source does not fetch files)", blockID)
+		return path.Join(currentRoot.imageURL(), pageID, blockID+ext), nil
+	}
+
+	fileURL, err := getFileURL(blockID)
+	if err != nil {
+		return "", fmt.Errorf("cannot get file %s: %w", blockID, err)
+	}
+
+	if ext == "" {
+		if u, err := url.Parse(fileURL); err == nil {
+			ext = path.Ext(u.Path)
+		}
+	}
+
+	fileName := blockID + ext
+	saveFile(pageID, fileName, fileURL, wg, errCh)
+	return path.Join(currentRoot.imageURL(), pageID, fileName), nil
+}
+
+// existingMediaFile 이미 내려받은 파일의 이름. 파일 이름에 확장자가 없으면 다운로드 주소에서 정하므로 같은 블록 ID 의 파일을 찾는다.
+func existingMediaFile(pageID, blockID, ext string) (string, bool) {
+	names := []string{blockID + ext}
+	if ext == "" {
+		matches, _ := filepath.Glob(filepath.Join(currentRoot.ImgDir, pageID, blockID+".*"))
+		for _, match := range matches {
+			names = append(names, filepath.Base(match))
+		}
+	}
+
+	for _, name := range names {
+		if skipExistingFile(pageID, name) {
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,144 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shinychan95/Chan/markdown"
+)
+
+// 멘션(‣)은 텍스트가 "‣" 이고 서식으로 대상이 지정된다.
+//   페이지: ["‣", [["p", "<page id>", "<space id>"]]]
+//   사용자: ["‣", [["u", "<user id>"]]]
+//   날짜:   ["‣", [["d", {"type": "date", "start_date": "2024-01-15"}]]]
+
+// 사용자 이름 캐시 (사용자 ID → 이름)
+var (
+	userNames      = make(map[string]string)
+	userNamesMutex sync.Mutex
+)
+
+// pageMention 내보내는 글이면 글 주소로, 아니면 노션 공개 페이지 주소로 링크합니다.
+func pageMention(pageID string) string {
+	if page, ok := lookupPage(pageID); ok {
+		return markdown.Link(markdown.Escape(page.Title), page.URL)
+	}
+
+	title := "Untitled"
+	if block, err := source.Block(pageID); err == nil {
+		if t := parsePlainTitle(block.Properties.String); t != "" {
+			title = t
+		}
+	} else {
+		log.Printf("Warning: cannot get mentioned page %s: %v", pageID, err)
+	}
+
+	return markdown.Link(markdown.Escape(title), notionURL(pageID))
+}
+
+// userMention notion_user 테이블의 이름으로 사용자 멘션을 표시합니다.
+func userMention(userID string) string {
+	userNamesMutex.Lock()
+	defer userNamesMutex.Unlock()
+
+	name, ok := userNames[userID]
+	if !ok {
+		var err error
+		if name, err = source.UserName(userID); err != nil {
+			log.Printf("Warning: cannot get mentioned user %s: %v", userID, err)
+		}
+		userNames[userID] = name
+	}
+
+	if name == "" {
+		name = "Unknown"
+	}
+	return "@" + markdown.Escape(name)
+}
+
+// dateMention 날짜 멘션을 "2024-01-15", "2024-01-15 10:30", "2024-01-15 → 2024-01-20" 형식으로 표시합니다.
+func dateMention(date *DateValue) string {
+	if date == nil || date.StartDate == "" {
+		return ""
+	}
+
+	format := func(d, t string) string {
+		parsed, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			return d
+		}
+		if t != "" {
+			return fmt.Sprintf("%s %s", parsed.Format("2006-01-02"), t)
+		}
+		return parsed.Format("2006-01-02")
+	}
+
+	text := format(date.StartDate, date.StartTime)
+	if date.EndDate != "" {
+		text += " → " + format(date.EndDate, date.EndTime)
+	}
+	return text
+}
+
+func resetUserNames() {
+	userNamesMutex.Lock()
+	defer userNamesMutex.Unlock()
+	userNames = make(map[string]string)
+}
+
+// collectMentions properties JSON 에서 멘션된 페이지와 사용자 ID 를 찾습니다.
+func collectMentions(properties string) (pageIDs, userIDs []string) {
+	walkAnnotations(properties, func(key string, value interface{}) {
+		id, isID := value.(string)
+		switch {
+		case key == "p" && isID:
+			pageIDs = append(pageIDs, id)
+		case key == "u" && isID:
+			userIDs = append(userIDs, id)
+		}
+	})
+
+	return
+}
+
+// containsAnnotation properties JSON 에 값을 가진 서식(예: ["e", "x+1"] 수식)이 있는지 확인합니다.
+func containsAnnotation(properties, key string) (found bool) {
+	walkAnnotations(properties, func(k string, value interface{}) {
+		_, isString := value.(string)
+		found = found || (k == key && isString)
+	})
+
+	return
+}
+
+// walkAnnotations properties JSON 안의 모든 [서식, 값] 쌍을 방문합니다.
+// 예: [["‣",[["p","<page id>"]]]] → visit("p", "<page id>")
+func walkAnnotations(properties string, visit func(key string, value interface{})) {
+	var props interface{}
+	if err := json.Unmarshal([]byte(properties), &props); err != nil {
+		return
+	}
+
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			for _, child := range value {
+				walk(child)
+			}
+		case []interface{}:
+			if len(value) == 2 {
+				if key, ok := value[0].(string); ok && len(key) == 1 {
+					visit(key, value[1])
+				}
+			}
+			for _, child := range value {
+				walk(child)
+			}
+		}
+	}
+	walk(props)
+}
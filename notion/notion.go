@@ -1,146 +1,92 @@
 package notion
 
 import (
-	"database/sql"
 	"encoding/json"
-	"log"
-
-	"github.com/shinychan95/Chan/utils"
+	"fmt"
 )
 
 var (
 	ApiKey     string
 	ApiVersion = "2022-06-28"
-	PostDir    string
-	ImgDir     string
-	db         *sql.DB
+	ApiBaseURL = "https://api.notion.com/v1"
+	source     Source
 )
 
-func Init(apiKey, postDir, imgDir, dbPath string) {
+func Init(apiKey string, src Source) {
 	ApiKey = apiKey
-	PostDir = postDir
-	ImgDir = imgDir
-
-	var err error
-	db, err = sql.Open("sqlite3", dbPath)
-	utils.CheckError(err)
+	source = src
+	resetExportedPages()
+	resetUserNames()
 }
 
-func Close() {
-	err := db.Close()
-	utils.CheckError(err)
+func Close() error {
+	return source.Close()
 }
 
 //////////////////////
 // Get Data From DB //
 //////////////////////
 
-func getCollectionId(rootID string) (colId string) {
-	query := "SELECT collection_id FROM block WHERE id = ? AND type = 'collection_view'"
-	log.Printf("Executing query: %s, with rootID: %s", query, rootID)
-	rows, err := db.Query(query, rootID)
-	utils.CheckError(err)
-	defer rows.Close()
-
-	for rows.Next() {
-		err = rows.Scan(&colId)
-		utils.CheckError(err)
-
-		if rows.Next() {
-			utils.ExecError("more than one row returned")
-		}
+func getCollectionId(rootID string) (string, error) {
+	colId, err := source.CollectionID(rootID)
+	if err != nil {
+		return "", err
 	}
-
 	if colId == "" {
-		utils.ExecError("cannot get collection id")
+		return "", fmt.Errorf("cannot get collection id of %s", rootID)
 	}
 
-	return
+	return colId, nil
 }
 
-func getCollectionSchema(collectionId string) (schemaMap map[string]Schema) {
-	query := "SELECT schema FROM collection WHERE id = ?"
-	log.Printf("Executing query: %s, with collectionId: %s", query, collectionId)
-	rows, err := db.Query(query, collectionId)
-	utils.CheckError(err)
-	defer rows.Close()
-
-	var rawSchema string
-	for rows.Next() {
-		err = rows.Scan(&rawSchema)
-		utils.CheckError(err)
-
-		if rows.Next() {
-			utils.ExecError("more than one row returned")
-		}
-
+func getCollectionSchema(collectionId string) (schemaMap map[string]Schema, err error) {
+	rawSchema, err := source.CollectionSchema(collectionId)
+	if err != nil {
+		return nil, err
 	}
 
-	err = json.Unmarshal([]byte(rawSchema), &schemaMap)
-	utils.CheckError(err)
-
-	return
-}
-
-func getPagesWithProperties(parentId string, schema map[string]Schema) (pages []Page) {
-	query := "SELECT id, properties FROM block WHERE parent_id = ? AND type = 'page' AND is_template IS NULL AND alive = 1"
-	log.Printf("Executing query: %s, with parentId: %s", query, parentId)
-	rows, err := db.Query(query, parentId)
-	utils.CheckError(err)
-	defer rows.Close()
-
-	for rows.Next() {
-		var (
-			id            string
-			rawProperties string
-		)
-		err = rows.Scan(&id, &rawProperties)
-		utils.CheckError(err)
-
-		page := Page{ID: id}
-		parsePageProperties(&page, rawProperties, schema)
-
-		pages = append(pages, page)
+	if err = json.Unmarshal([]byte(rawSchema), &schemaMap); err != nil {
+		return nil, fmt.Errorf("invalid schema of collection %s: %w", collectionId, err)
 	}
 
 	return
 }
 
-func getRootType(rootID string) (t string) {
-	query := "SELECT type FROM block WHERE id = ?"
-	log.Printf("Executing query: %s, with rootID: %s", query, rootID)
-	rows, err := db.Query(query, rootID)
-	utils.CheckError(err)
-	defer rows.Close()
+// getPagesWithProperties 데이터베이스에서 shouldExport 인 글들을 가져옵니다. 속성을 읽지 못한 글은 failures 로 따로 반환한다.
+// 속성을 읽지 못해도 Status 로 내보내지 않을 글(초안 등)임을 알 수 있으면 실패로 취급하지 않는다.
+func getPagesWithProperties(parentId string, schema map[string]Schema, shouldExport func(Page) bool) (pages []Page, failures []*PageError, err error) {
+	records, err := source.Pages(parentId)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	for rows.Next() {
-		err = rows.Scan(&t)
-		utils.CheckError(err)
+	for _, record := range records {
+		page := Page{ID: record.ID}
+		if err := parsePageProperties(&page, record.Properties, schema); err != nil {
+			if status, ok := parsePageStatus(record.Properties, schema); ok && !shouldExport(Page{ID: record.ID, Status: status}) {
+				continue
+			}
+			failures = append(failures, &PageError{PageID: record.ID, Err: err})
+			continue
+		}
 
-		if rows.Next() {
-			utils.ExecError("more than one row returned")
-			return
+		if shouldExport(page) {
+			pages = append(pages, page)
 		}
 	}
 
 	return
 }
 
-func getBlockData(blockID string) (block Block) {
-	query := "SELECT id, type, content, properties, format FROM block WHERE id = ?"
-	rows, err := db.Query(query, blockID)
-	utils.CheckError(err)
-	defer rows.Close()
-
-	for rows.Next() {
-		err = rows.Scan(&block.ID, &block.Type, &block.Content, &block.Properties, &block.Format)
-		utils.CheckError(err)
+func getRootType(rootID string) (string, error) {
+	return source.RootType(rootID)
+}
 
-		if rows.Next() {
-			utils.ExecError("more than one row returned")
-			return
-		}
+func getBlockData(blockID string) (Block, error) {
+	block, err := source.Block(blockID)
+	if err != nil {
+		return Block{}, fmt.Errorf("cannot get block %s: %w", blockID, err)
 	}
 
-	return
+	return block, nil
 }
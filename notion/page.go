@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -18,6 +19,9 @@ import (
 // 글로벌 카운터 (글 번호 생성용)
 var postCounter int64 = 0
 
+// INFO - Author 의 경우, static 하게 입력한다.
+const defaultAuthor = "chanyoung.kim"
+
 // ResetPostCounter 카운터를 리셋합니다 (테스트용)
 func ResetPostCounter() {
 	atomic.StoreInt64(&postCounter, 0)
@@ -37,6 +41,74 @@ type Page struct {
 	Categories []string
 	Tags       []string
 	Published  time.Time
+	// FrontMatter 루트 설정에서 추가되는 머리말 항목 (예: layout: til)
+	FrontMatter map[string]string
+	// URL 블로그에 게시된 글의 주소 (registerPage 에서 설정)
+	URL string
+	// ParentID 상위 글(하위 페이지로 내보낸 경우)의 페이지 ID, breadcrumb 에 사용
+	ParentID string
+}
+
+// PageError 글 하나를 내보내지 못한 이유. 실패한 글은 건너뛰고 나머지 글은 그대로 내보낸다.
+type PageError struct {
+	PageID string
+	Title  string // 글 목록을 만들기 전에 실패한 경우 빈 문자열
+	Root   string // 글을 내보내던 루트의 ID (비어 있으면 실패를 모으는 루트의 ID)
+	Err    error
+}
+
+func (e *PageError) Error() string {
+	if e.Title == "" {
+		return fmt.Sprintf("page %s: %v", e.PageID, e.Err)
+	}
+	return fmt.Sprintf("page %q (%s): %v", e.Title, e.PageID, e.Err)
+}
+
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+// recoverPage 글을 변환하다 발생한 panic 을 해당 글의 실패로 기록합니다. (defer 로 호출)
+// 하위 페이지를 모두 찾았는지 알 수 없으므로 루트의 글 목록도 완전하지 않은 것으로 본다.
+func recoverPage(page Page, errCh chan error) {
+	if r := recover(); r != nil {
+		discoveryFailed(currentRoot.ID)
+		errCh <- &PageError{PageID: page.ID, Title: page.Title, Err: fmt.Errorf("panic: %v", r)}
+	}
+}
+
+// 내보내는 글 목록 (페이지 ID → 글), 다른 글에서 링크할 때 사용
+var (
+	exportedPages      = make(map[string]Page)
+	exportedPagesMutex sync.RWMutex
+)
+
+// registerPage 내보낼 글의 URL 을 정하고 글 목록에 등록합니다.
+func registerPage(page *Page) {
+	page.URL = currentRoot.permalink(*page)
+
+	exportedPagesMutex.Lock()
+	defer exportedPagesMutex.Unlock()
+	exportedPages[page.ID] = *page
+}
+
+// lookupPage 내보내는 글 목록에서 페이지를 찾습니다.
+func lookupPage(pageID string) (Page, bool) {
+	exportedPagesMutex.RLock()
+	defer exportedPagesMutex.RUnlock()
+	page, ok := exportedPages[pageID]
+	return page, ok
+}
+
+func resetExportedPages() {
+	exportedPagesMutex.Lock()
+	defer exportedPagesMutex.Unlock()
+	exportedPages = make(map[string]Page)
+}
+
+// notionURL 노션 공개 페이지 주소입니다. (내보내지 않는 페이지를 링크할 때 사용)
+func notionURL(pageID string) string {
+	return "https://www.notion.so/" + strings.ReplaceAll(pageID, "-", "")
 }
 
 // escapeYAMLString YAML에서 특수문자를 적절히 이스케이프합니다
@@ -64,101 +136,283 @@ func (pg *Page) GetMetaString() string {
 	sb.WriteString("categories: [" + utils.SliceToString(pg.Categories, nil) + "]\n")
 	sb.WriteString("tags: [" + utils.SliceToString(pg.Tags, strings.ToLower) + "]\n")
 
+	// 추가 머리말은 항상 같은 결과가 나오도록 키 순서대로 출력
+	keys := make([]string, 0, len(pg.FrontMatter))
+	for key := range pg.FrontMatter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", key, escapeYAMLString(pg.FrontMatter[key])))
+	}
+
 	sb.WriteString("---\n")
 
 	return sb.String()
 }
 
-func handlePage(page Page, wg *sync.WaitGroup, errCh chan error) {
+func handlePage(page Page, includeSubPages bool, wg *sync.WaitGroup, errCh chan error) error {
 	fmt.Println("Page title:", page.Path)
 
 	if page.ID == "1519a0a9-70f1-444e-95b4-f6e6fac46131" {
 		fmt.Println("")
 	}
 
+	pageBlock, err := getBlockData(page.ID)
+	if err != nil {
+		discoveryFailed(currentRoot.ID)
+		return err
+	}
+
+	// 페이지의 수정 시각이 이전 동기화와 같으면 본문 블록을 가져오지 않는다.
+	// 하위 페이지를 내보내는 경우에는 하위 페이지를 찾기 위해 본문이 필요하다.
+	if !includeSubPages {
+		if previous, ok := unchangedPageBlock(page.ID, pageBlock.LastEditedTime, postFilePath(page)); ok {
+			keepUnchangedPage(page, previous)
+			return nil
+		}
+	}
+
 	// page block 하위 모든 block parsing
-	pageBlock := getBlockData(page.ID)
-	parseChildBlocks(&pageBlock)
+	if err = loadChildBlocks(&pageBlock); err != nil {
+		discoveryFailed(currentRoot.ID)
+		return err
+	}
+
+	return exportPage(page, pageBlock, includeSubPages, wg, errCh)
+}
+
+// loadPageBlock 페이지 블록과 하위 모든 블록을 가져옵니다.
+func loadPageBlock(pageID string) (Block, error) {
+	pageBlock, err := getBlockData(pageID)
+	if err != nil {
+		return Block{}, err
+	}
+	if err = loadChildBlocks(&pageBlock); err != nil {
+		return Block{}, err
+	}
+
+	return pageBlock, nil
+}
+
+// loadChildBlocks 페이지 블록의 하위 모든 블록을 가져오고 번호 목록에 번호를 매깁니다.
+func loadChildBlocks(pageBlock *Block) error {
+	if err := parseChildBlocks(pageBlock); err != nil {
+		return err
+	}
 	setNumberedListValue(&pageBlock.Children)
 
+	return nil
+}
+
+// withFrontMatter 머리말에 값이 없으면 추가합니다. (설정한 값이 우선)
+// 머리말은 상위 페이지와 공유될 수 있으므로 복사해서 추가한다.
+func withFrontMatter(frontMatter map[string]string, key, value string) map[string]string {
+	if _, ok := frontMatter[key]; ok {
+		return frontMatter
+	}
+
+	copied := map[string]string{key: value}
+	for k, v := range frontMatter {
+		copied[k] = v
+	}
+	return copied
+}
+
+// writePage 파싱된 페이지 블록을 마크다운으로 변환하여 루트의 PostDir 에 저장합니다.
+// 변환에 실패하면 파일을 쓰지 않고 오류를 반환한다.
+func writePage(page Page, pageBlock Block, wg *sync.WaitGroup, errCh chan error) error {
+	postDir := currentRoot.PostDir
+	markdownFilePath := postFilePath(page)
+
+	// 이전 동기화 이후 바뀌지 않은 글은 변환하지 않고 기존 파일을 그대로 둔다.
+	lastEditedTime := latestEditedTime(pageBlock)
+	if previous, ok := unchangedPage(page.ID, lastEditedTime, markdownFilePath); ok {
+		keepUnchangedPage(page, previous)
+		return nil
+	}
+
 	//////////////////////
 	// markdown 결과 출력 //
 	//////////////////////
 
 	var markdownOutput string
 
+	// 수식이 있는 글은 MathJax 를 켠다. (Chirpy 의 math: true)
+	if containsEquation(pageBlock.Children) {
+		page.FrontMatter = withFrontMatter(page.FrontMatter, "math", "true")
+	}
+	// {% raw %} 로 감쌀 수 없는 코드가 있으면 글 전체에서 Liquid 를 끈다. (이 글의 테마 include 는 동작하지 않음)
+	if containsUnsafeLiquid(pageBlock.Children) {
+		warnPage(page.ID, "code with {%% endraw %%}, render_with_liquid is disabled")
+		page.FrontMatter = withFrontMatter(page.FrontMatter, "render_with_liquid", "false")
+	}
+
 	// 내부 헤더
 	markdownOutput += page.GetMetaString() + "\n"
 
 	// Table of Contents 생성을 위해 헤더 정보 수집
-	headers := CollectHeaders(pageBlock.Children)
+	headers, err := CollectHeaders(pageBlock.Children)
+	if err != nil {
+		return err
+	}
 
 	// 내부 컨텐츠
-	for _, block := range pageBlock.Children {
-		markdownOutput += ParseBlock(page.ID, block, 0, headers, wg, errCh)
+	content, err := ParseBlocks(page.ID, pageBlock.Children, 0, headers, wg, errCh)
+	if err != nil {
+		return err
+	}
+	markdownOutput += content
+
+	updatePageReport(currentRoot.ID, page.ID, func(pr *PageReport) {
+		pr.Title = page.Title
+		pr.File = markdownFilePath
+	})
+	entry := ManifestPage{
+		Root:           currentRoot.ID,
+		LastEditedTime: lastEditedTime,
+		PageEditedTime: pageBlock.LastEditedTime,
+		Config:         currentRoot.configHash(),
+		Hash:           contentHash(markdownOutput),
+		File:           markdownFilePath,
+		Dependencies:   dependencyStates(page.ID),
 	}
 
-	if _, err := os.Stat(PostDir); os.IsNotExist(err) {
-		os.MkdirAll(PostDir, os.ModePerm)
+	// 수정 시각만 바뀌고 내용이 같으면 파일을 다시 쓰지 않는다.
+	if previous, ok := previousPage(page.ID); ok && previous.Hash == entry.Hash && previous.File == markdownFilePath {
+		if _, err = os.Stat(markdownFilePath); err == nil {
+			recordPage(page.ID, entry)
+			log.Printf("📄 Page unchanged: %s (%s)", page.Title, markdownFilePath)
+			return nil
+		}
 	}
 
-	datePrefix := page.Published.Format("2006-01-02")
-	markdownFileName := fmt.Sprintf("%s-%s.md", datePrefix, utils.SanitizeFileName(page.Path))
-	markdownFilePath := filepath.Join(PostDir, "", markdownFileName)
+	// 직접 쓴 글 등 Chan 이 만들지 않은 파일은 덮어쓰지 않는다.
+	if _, err = os.Stat(markdownFilePath); err == nil && !ownsFile(markdownFilePath, entry.Hash) {
+		return fmt.Errorf("%s already exists and was not created by Chan (remove it to let Chan write the post)", markdownFilePath)
+	}
 
-	err := ioutil.WriteFile(markdownFilePath, []byte(markdownOutput), 0644)
-	utils.CheckError(err)
+	if err = os.MkdirAll(postDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	if err = ioutil.WriteFile(markdownFilePath, []byte(markdownOutput), 0644); err != nil {
+		return err
+	}
+	recordPage(page.ID, entry)
 
 	log.Printf("📄 Page saved: %s (%s)", page.Title, markdownFilePath)
+	return nil
 }
 
-func parsePageProperties(page *Page, rawProperties string, schema map[string]Schema) {
-	var propertiesMap map[string][][]interface{}
-	err := json.Unmarshal([]byte(rawProperties), &propertiesMap)
-	utils.CheckError(err)
+// keepUnchangedPage 이전 동기화 이후 바뀌지 않은 글의 기존 파일을 그대로 두고, 이전 상태를 다음 manifest 에 기록합니다.
+func keepUnchangedPage(page Page, previous ManifestPage) {
+	recordPage(page.ID, previous)
+	updatePageReport(currentRoot.ID, page.ID, func(pr *PageReport) {
+		pr.Title = page.Title
+		pr.File = previous.File
+		pr.Status = PageUnchanged
+	})
+}
+
+// postFilePath 루트의 PostDir 안의 글 파일 경로
+func postFilePath(page Page) string {
+	return filepath.Join(currentRoot.PostDir, postFileName(page))
+}
 
-	// INFO - Author 의 경우, static 하게 입력한다.
-	page.Author = "chanyoung.kim"
+// postFileName Jekyll 포스트 파일 이름(날짜-경로.md)을 만듭니다.
+func postFileName(page Page) string {
+	datePrefix := page.Published.Format("2006-01-02")
+	return fmt.Sprintf("%s-%s.md", datePrefix, postSlug(page))
+}
+
+// postSlug 파일 이름에서 날짜를 제외한 부분으로, Jekyll permalink 의 :title 에 해당합니다.
+// Path 의 '/' 는 페이지 계층 구분자로 보고, 각 단계를 '-' 로 이어 붙입니다.
+func postSlug(page Page) string {
+	segments := strings.Split(page.Path, "/")
+	for i, segment := range segments {
+		segments[i] = utils.SanitizeFileName(segment)
+	}
+
+	return strings.Join(segments, "-")
+}
+
+// parsePageProperties 데이터베이스 글의 속성(제목, 카테고리, 발행일 등)을 읽습니다.
+func parsePageProperties(page *Page, rawProperties string, schema map[string]Schema) error {
+	props, err := decodeProperties(page.ID, rawProperties)
+	if err != nil {
+		return err
+	}
+
+	page.Author = defaultAuthor
 
 	// 기본값 설정
 	page.Published = time.Now()                                          // 현재 시간을 기본값으로 설정
 	page.Path = fmt.Sprintf("post-%d", atomic.AddInt64(&postCounter, 1)) // 글 번호를 기본값으로 설정
 
-	for key, value := range propertiesMap {
-		schemaInfo := schema[key]
-		propertyValue := value[0]
-
-		switch schemaInfo.Name {
+	for key, value := range props {
+		switch schema[key].Name {
 		case "Categories":
 			// `block` 테이블의 `properties`에는 옵션의 '값'이 쉼표로 구분된 문자열로 저장되어 있습니다.
 			// 예: [["Value1,Value2"]]
-			page.Categories = strings.Split(propertyValue[0].(string), ",")
+			page.Categories = props.Select(key)
 		case "Tags":
-			page.Tags = strings.Split(propertyValue[0].(string), ",")
+			page.Tags = props.Select(key)
 		case "Status":
 			// `block` 테이블의 `properties`에는 상태의 '값'이 직접 저장되어 있습니다.
 			// 예: [["Archived"]]
-			page.Status = propertyValue[0].(string)
+			page.Status = value.Plain()
 		case "Title":
-			page.Title = propertyValue[0].(string)
+			page.Title = value.Plain()
 		case "Path":
 			// Path가 비어있지 않은 경우에만 설정
-			if pathValue := propertyValue[0].(string); pathValue != "" {
+			if pathValue := value.Plain(); pathValue != "" {
 				page.Path = pathValue
 			}
 		case "Published":
-			dateProperty := propertyValue[1].([]interface{})[0].([]interface{})[1].(map[string]interface{})
-			dateString := dateProperty["start_date"].(string)
-			timeString, ok := dateProperty["start_time"]
-			if !ok {
-				timeString = "00:00"
+			// 예: [["‣", [["d", {"type": "datetime", "start_date": "2024-01-15", "start_time": "10:30"}]]]]
+			published, err := parsePublished(value.Date())
+			if err != nil {
+				return &PropertyError{BlockID: page.ID, Property: key, Err: err}
 			}
-			dateTime := dateString + "T" + timeString.(string) + ":00"
-			location, _ := time.LoadLocation("Asia/Seoul")
-			page.Published, err = time.ParseInLocation("2006-01-02T15:04:05", dateTime, location)
-			utils.CheckError(err)
+			page.Published = published
 		}
 	}
 
-	return
+	return nil
+}
+
+// parsePageStatus 다른 속성이 잘못되었더라도 Status 속성만 읽습니다. (Status 를 읽지 못하면 false)
+func parsePageStatus(rawProperties string, schema map[string]Schema) (string, bool) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(rawProperties), &raw); err != nil {
+		return "", false
+	}
+
+	for key, value := range raw {
+		if schema[key].Name != "Status" {
+			continue
+		}
+		var text RichText
+		if err := json.Unmarshal(value, &text); err != nil {
+			return "", false
+		}
+		return text.Plain(), true
+	}
+
+	return "", true // Status 가 비어 있는 글
+}
+
+// parsePublished 발행일 속성을 한국 시간으로 읽습니다. (시간이 없으면 00:00)
+func parsePublished(date *DateValue) (time.Time, error) {
+	if date == nil || date.StartDate == "" {
+		return time.Time{}, fmt.Errorf("not a date")
+	}
+
+	timeString := date.StartTime
+	if timeString == "" {
+		timeString = "00:00"
+	}
+	location, _ := time.LoadLocation("Asia/Seoul")
+	return time.ParseInLocation("2006-01-02T15:04", date.StartDate+"T"+timeString, location)
 }
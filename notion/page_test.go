@@ -164,6 +164,26 @@ func TestParsePagePropertiesWithEmptyPath(t *testing.T) {
 	assert.Equal(t, "Published", page.Status)
 }
 
+func TestParsePagePropertiesWithInvalidPublished(t *testing.T) {
+	// Arrange
+	page := &Page{ID: "page-1"}
+	rawProperties := `{
+		"title": [["Test Title"]],
+		"published": [["2024-01-15"]]
+	}`
+
+	schema := map[string]Schema{
+		"title":     {Name: "Title"},
+		"published": {Name: "Published"},
+	}
+
+	// Act
+	err := parsePageProperties(page, rawProperties, schema)
+
+	// Assert
+	assert.EqualError(t, err, `block page-1: invalid property "published": not a date`)
+}
+
 func TestPostCounterIncrement(t *testing.T) {
 	// Arrange
 	ResetPostCounter() // 카운터 리셋
@@ -0,0 +1,181 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// 노션 블록의 properties 는 속성 이름(혹은 데이터베이스 속성 ID) → rich text 형식이다.
+//   {"title": [["굵은 ", [["b"]]], ["링크", [["a", "https://..."]]], ["‣", [["d", {"type": "date", ...}]]]]}
+// rich text 는 [텍스트, 서식 목록] 조각(segment)의 목록이고, 서식은 [종류] 혹은 [종류, 값] 이다.
+// 아래 타입들은 이 형식을 검증하며 디코딩하고, 잘못된 형식은 블록 ID 와 속성 이름을 담은 오류로 반환한다.
+
+// Properties 디코딩한 블록 properties (속성 이름 → rich text)
+type Properties map[string]RichText
+
+// RichText 텍스트 조각의 목록
+type RichText []Segment
+
+// Segment 텍스트와 서식 목록
+type Segment struct {
+	Text        string
+	Annotations []Annotation
+}
+
+// Annotation 텍스트 서식. 예: ["b"], ["a", "https://..."], ["d", {...}]
+type Annotation struct {
+	Type  string
+	Value string     // a(링크 주소), h(색상), e(수식), p(페이지 ID), u(사용자 ID)
+	Date  *DateValue // d(날짜 멘션)
+}
+
+// DateValue 날짜 멘션 및 날짜 속성의 값
+type DateValue struct {
+	Type      string `json:"type"` // date, datetime, daterange, datetimerange
+	StartDate string `json:"start_date"`
+	StartTime string `json:"start_time"`
+	EndDate   string `json:"end_date"`
+	EndTime   string `json:"end_time"`
+	TimeZone  string `json:"time_zone"`
+}
+
+// PropertyError 블록 properties (혹은 format) 를 디코딩하지 못한 경우의 오류
+type PropertyError struct {
+	BlockID  string
+	Property string // properties 전체가 잘못된 경우 빈 문자열, format 이 잘못된 경우 "format"
+	Err      error
+}
+
+func (e *PropertyError) Error() string {
+	if e.Property == "" {
+		return fmt.Sprintf("block %s: invalid properties: %v", e.BlockID, e.Err)
+	}
+	return fmt.Sprintf("block %s: invalid property %q: %v", e.BlockID, e.Property, e.Err)
+}
+
+func (e *PropertyError) Unwrap() error {
+	return e.Err
+}
+
+// stringValueAnnotations 문자열 값을 가져야 하는 서식
+var stringValueAnnotations = map[string]bool{"a": true, "h": true, "e": true, "p": true, "u": true}
+
+func (s *Segment) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("segment is not an array: %s", data)
+	}
+	if len(raw) == 0 {
+		return fmt.Errorf("empty segment")
+	}
+	if err := json.Unmarshal(raw[0], &s.Text); err != nil {
+		return fmt.Errorf("segment text is not a string: %s", raw[0])
+	}
+	if len(raw) > 1 {
+		if err := json.Unmarshal(raw[1], &s.Annotations); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *Annotation) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw) == 0 {
+		return fmt.Errorf("invalid annotation: %s", data)
+	}
+	if err := json.Unmarshal(raw[0], &a.Type); err != nil {
+		return fmt.Errorf("annotation type is not a string: %s", raw[0])
+	}
+	if len(raw) < 2 {
+		return nil
+	}
+
+	// 페이지 멘션은 ["p", "<page id>", "<space id>"] 처럼 값 뒤에 항목이 더 있을 수 있다.
+	switch {
+	case a.Type == "d":
+		a.Date = new(DateValue)
+		if err := json.Unmarshal(raw[1], a.Date); err != nil {
+			return fmt.Errorf("invalid date annotation: %s", raw[1])
+		}
+	case stringValueAnnotations[a.Type]:
+		if err := json.Unmarshal(raw[1], &a.Value); err != nil {
+			return fmt.Errorf("%q annotation value is not a string: %s", a.Type, raw[1])
+		}
+	}
+
+	return nil
+}
+
+// decodeProperties 블록의 properties 를 디코딩합니다. properties 가 없으면 빈 Properties 를 반환한다.
+func decodeProperties(blockID, rawProperties string) (Properties, error) {
+	props := make(Properties)
+	if rawProperties == "" {
+		return props, nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(rawProperties), &raw); err != nil {
+		return props, &PropertyError{BlockID: blockID, Err: err}
+	}
+
+	for key, value := range raw {
+		var text RichText
+		if err := json.Unmarshal(value, &text); err != nil {
+			return props, &PropertyError{BlockID: blockID, Property: key, Err: err}
+		}
+		props[key] = text
+	}
+
+	return props, nil
+}
+
+// Plain 서식 없이 텍스트만 이어 붙입니다.
+func (t RichText) Plain() string {
+	var sb strings.Builder
+	for _, segment := range t {
+		sb.WriteString(segment.Text)
+	}
+	return sb.String()
+}
+
+// Annotation 조각에서 kind 서식을 찾습니다.
+func (s Segment) Annotation(kind string) (Annotation, bool) {
+	for _, a := range s.Annotations {
+		if a.Type == kind {
+			return a, true
+		}
+	}
+	return Annotation{}, false
+}
+
+// Date 첫 번째 날짜 멘션 값 (날짜 속성은 ["‣", [["d", {...}]]] 로 저장된다)
+func (t RichText) Date() *DateValue {
+	for _, segment := range t {
+		if a, ok := segment.Annotation("d"); ok && a.Date != nil {
+			return a.Date
+		}
+	}
+	return nil
+}
+
+// Plain 속성의 텍스트 (속성이 없으면 빈 문자열)
+func (p Properties) Plain(key string) string {
+	return p[key].Plain()
+}
+
+// Checkbox 체크박스 속성 ([["Yes"]] 이면 체크됨)
+func (p Properties) Checkbox(key string) bool {
+	return p.Plain(key) == "Yes"
+}
+
+// Select 선택/다중 선택 속성. 옵션 값은 쉼표로 구분된 문자열로 저장되어 있다. 예: [["Go,CI"]]
+func (p Properties) Select(key string) []string {
+	value := p.Plain(key)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,64 @@
+package notion
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeProperties(t *testing.T) {
+	// Arrange
+	raw := `{
+		"title": [["굵은 링크", [["b"], ["a", "https://example.com"]]], ["‣", [["p", "page-1", "space-1"]]]],
+		"published": [["‣", [["d", {"type": "datetime", "start_date": "2024-01-15", "start_time": "10:30"}]]]],
+		"checked": [["Yes"]],
+		"tags": [["Go,CI"]],
+		"unknown": [["값", [["z", {"future": true}]]]]
+	}`
+
+	// Act
+	props, err := decodeProperties("block-1", raw)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "굵은 링크‣", props.Plain("title"))
+	link, ok := props["title"][0].Annotation("a")
+	assert.True(t, ok)
+	assert.Equal(t, "https://example.com", link.Value)
+	assert.Equal(t, "page-1", props["title"][1].Annotations[0].Value)
+	assert.Equal(t, &DateValue{Type: "datetime", StartDate: "2024-01-15", StartTime: "10:30"}, props["published"].Date())
+	assert.True(t, props.Checkbox("checked"))
+	assert.False(t, props.Checkbox("missing"))
+	assert.Equal(t, []string{"Go", "CI"}, props.Select("tags"))
+	assert.Equal(t, "", props.Plain("missing"))
+}
+
+func TestDecodePropertiesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		property string
+	}{
+		{name: "not an object", raw: `[["text"]]`},
+		{name: "segment is not an array", raw: `{"title": ["text"]}`, property: "title"},
+		{name: "text is not a string", raw: `{"title": [[42]]}`, property: "title"},
+		{name: "link is not a string", raw: `{"caption": [["a", [["a", {"url": "x"}]]]]}`, property: "caption"},
+		{name: "invalid date", raw: `{"published": [["‣", [["d", "2024-01-15"]]]]}`, property: "published"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := decodeProperties("block-1", tt.raw)
+
+			// Assert
+			var propertyErr *PropertyError
+			require.True(t, errors.As(err, &propertyErr), "error: %v", err)
+			assert.Equal(t, "block-1", propertyErr.BlockID)
+			assert.Equal(t, tt.property, propertyErr.Property)
+			assert.Contains(t, err.Error(), "block-1")
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package notion
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PageStatus 글의 처리 결과
+type PageStatus string
+
+const (
+	PageExported  PageStatus = "exported"
+	PageUnchanged PageStatus = "unchanged" // 이전 동기화 이후 바뀌지 않아 기존 파일을 그대로 둠
+	PageFailed    PageStatus = "failed"    // 글 파일을 쓰지 못했거나 이미지를 내려받지 못함
+)
+
+// Report 한 번의 동기화에서 처리한 글들의 결과
+type Report struct {
+	Pages          []*PageReport `json:"pages"`
+	Removed        []string      `json:"removed,omitempty"`         // 더 이상 내보내지 않아 지운 글 파일
+	RemovedImages  []string      `json:"removed_images,omitempty"`  // 어떤 글도 사용하지 않아 지운 이미지 파일과 페이지 폴더
+	OrphanedImages []string      `json:"orphaned_images,omitempty"` // 어떤 글도 사용하지 않지만 지우지 않은(정리를 켜지 않은) 이미지
+	Failures       []*PageError  `json:"-"`                         // 실패한 글의 오류 (Pages 의 Errors 와 같은 내용)
+	Manifest       *Manifest     `json:"-"`                         // 다음 동기화에 사용할 글들의 상태
+}
+
+// PageReport 글 하나의 처리 결과
+type PageReport struct {
+	PageID           string     `json:"page_id"`
+	Title            string     `json:"title"`
+	Root             string     `json:"root"`
+	Status           PageStatus `json:"status"`
+	File             string     `json:"file,omitempty"`
+	ImagesDownloaded int        `json:"images_downloaded"`
+	ImagesSkipped    int        `json:"images_skipped"` // 이미 내려받은 이미지
+	Images           []string   `json:"images,omitempty"`
+	Warnings         []string   `json:"warnings,omitempty"`
+	Errors           []string   `json:"errors,omitempty"`
+}
+
+// 진행 중인 동기화의 글별 결과 (페이지 ID → 결과). 글들은 동시에 변환되므로 reportMutex 로 보호한다.
+var (
+	pageReports = make(map[string]*PageReport)
+	reportMutex sync.Mutex
+)
+
+func resetReport() {
+	reportMutex.Lock()
+	defer reportMutex.Unlock()
+	pageReports = make(map[string]*PageReport)
+}
+
+// updatePageReport 글의 결과를 (없으면 만들어서) 수정합니다.
+// 실패를 모으는 goroutine 에서도 호출되므로 currentRoot 대신 글의 루트 ID 를 받는다.
+func updatePageReport(rootID, pageID string, update func(*PageReport)) {
+	reportMutex.Lock()
+	defer reportMutex.Unlock()
+
+	pr, ok := pageReports[pageID]
+	if !ok {
+		pr = &PageReport{PageID: pageID, Root: rootID}
+		if page, found := lookupPage(pageID); found {
+			pr.Title = page.Title
+		}
+		pageReports[pageID] = pr
+	}
+	update(pr)
+}
+
+// warnPage 글을 변환하며 건너뛴 내용을 로그와 글의 결과에 남깁니다. (지원하지 않는 블록 등)
+func warnPage(pageID, format string, args ...interface{}) {
+	warning := fmt.Sprintf(format, args...)
+	log.Printf("Warning: %s", warning)
+	updatePageReport(currentRoot.ID, pageID, func(pr *PageReport) {
+		pr.Warnings = append(pr.Warnings, warning)
+	})
+}
+
+// reportImage 글의 이미지(미디어 파일 포함)를 내려받았는지, 이미 있어 건너뛰었는지 기록합니다.
+func reportImage(pageID, path string, downloaded bool) {
+	updatePageReport(currentRoot.ID, pageID, func(pr *PageReport) {
+		pr.Images = append(pr.Images, path)
+		if downloaded {
+			pr.ImagesDownloaded++
+		} else {
+			pr.ImagesSkipped++
+		}
+	})
+}
+
+// reportFailure 글의 실패를 기록합니다.
+func reportFailure(failure *PageError) {
+	updatePageReport(failure.Root, failure.PageID, func(pr *PageReport) {
+		if pr.Title == "" {
+			pr.Title = failure.Title
+		}
+		pr.Errors = append(pr.Errors, failure.Err.Error())
+	})
+}
+
+// buildReport 모은 글별 결과를 루트 순서, 제목 순으로 정리합니다.
+func buildReport(roots []Root, failures []*PageError) *Report {
+	reportMutex.Lock()
+	defer reportMutex.Unlock()
+
+	rootOrder := make(map[string]int, len(roots))
+	for i, root := range roots {
+		rootOrder[root.ID] = i
+	}
+
+	report := &Report{Failures: failures}
+	for _, pr := range pageReports {
+		if len(pr.Errors) > 0 || pr.File == "" {
+			pr.Status = PageFailed
+		} else if pr.Status == "" {
+			pr.Status = PageExported
+		}
+		report.Pages = append(report.Pages, pr)
+	}
+	sort.Slice(report.Pages, func(i, j int) bool {
+		a, b := report.Pages[i], report.Pages[j]
+		if rootOrder[a.Root] != rootOrder[b.Root] {
+			return rootOrder[a.Root] < rootOrder[b.Root]
+		}
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		return a.PageID < b.PageID
+	})
+
+	return report
+}
+
+// Exported 내보낸 글의 수
+func (r *Report) Exported() (count int) {
+	for _, pr := range r.Pages {
+		if pr.Status == PageExported {
+			count++
+		}
+	}
+	return
+}
+
+// ImagesDownloaded 새로 내려받은 이미지의 수
+func (r *Report) ImagesDownloaded() (count int) {
+	for _, pr := range r.Pages {
+		count += pr.ImagesDownloaded
+	}
+	return
+}
+
+// Unchanged 바뀌지 않아 그대로 둔 글의 수
+func (r *Report) Unchanged() (count int) {
+	for _, pr := range r.Pages {
+		if pr.Status == PageUnchanged {
+			count++
+		}
+	}
+	return
+}
+
+// String 글마다 한 줄(경고, 오류는 아래에 들여써서)로 정리한 결과. 바뀌지 않은 글은 개수만 표시합니다.
+// 예: ✅ Docs → _posts/2024-01-15-docs.md (이미지 2개 받음, 1개 건너뜀)
+func (r *Report) String() string {
+	var sb strings.Builder
+	for _, pr := range r.Pages {
+		if pr.Status == PageUnchanged {
+			continue
+		}
+
+		title := pr.Title
+		if title == "" {
+			title = pr.PageID
+		}
+
+		if pr.Status == PageExported {
+			sb.WriteString(fmt.Sprintf("✅ %s → %s", title, pr.File))
+		} else {
+			sb.WriteString(fmt.Sprintf("❌ %s", title))
+		}
+		if pr.ImagesDownloaded > 0 || pr.ImagesSkipped > 0 {
+			sb.WriteString(fmt.Sprintf(" (이미지 %d개 받음, %d개 건너뜀)", pr.ImagesDownloaded, pr.ImagesSkipped))
+		}
+		sb.WriteString("\n")
+
+		for _, warning := range pr.Warnings {
+			sb.WriteString("    ⚠️ " + warning + "\n")
+		}
+		for _, err := range pr.Errors {
+			sb.WriteString("    ❌ " + err + "\n")
+		}
+	}
+	for _, file := range r.Removed {
+		sb.WriteString(fmt.Sprintf("🗑️ %s\n", file))
+	}
+	for _, image := range r.RemovedImages {
+		sb.WriteString(fmt.Sprintf("🗑️ %s\n", image))
+	}
+	if len(r.OrphanedImages) > 0 {
+		sb.WriteString(fmt.Sprintf("🧹 사용하지 않는 이미지 %d개 (지우지 않음)\n", len(r.OrphanedImages)))
+	}
+	if unchanged := r.Unchanged(); unchanged > 0 {
+		sb.WriteString(fmt.Sprintf("변경 없는 글 %d개\n", unchanged))
+	}
+	return sb.String()
+}
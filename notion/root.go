@@ -0,0 +1,363 @@
+package notion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/shinychan95/Chan/markdown"
+	"github.com/shinychan95/Chan/utils"
+)
+
+// Root 는 내보낼 루트 블록(collection view 혹은 일반 페이지)과 출력 설정입니다.
+// 한 번의 동기화에서 여러 루트를 각각 다른 폴더(_posts, _til 등)로 내보낼 수 있습니다.
+type Root struct {
+	ID              string
+	PostDir         string
+	ImgDir          string
+	Statuses        []string          // 내보낼 Status 값 (기본값: Published, Archived)
+	IncludeSubPages bool              // 일반 페이지 루트의 하위 페이지도 내보낼지 여부
+	FrontMatter     map[string]string // 글 머리말 기본값 (예: author, layout, categories)
+	Permalink       string            // 글 URL 형식 (기본값: /posts/:title/)
+	InlineMath      string            // 인라인 수식 구분자 (기본값: $...$)
+	BlockMath       string            // 블록 수식 구분자 (기본값: $$...$$)
+	EmbedIncludes   map[string]string // 임베드 include 형식 (youtube, vimeo, video, audio)
+	CalloutPrompts  map[string]string // 콜아웃 색상 → Chirpy prompt (info, tip, warning, danger)
+	ColorStyle      string            // 글자색/배경색 표현 방식 (class(기본값) | inline | none)
+	Stylesheet      string            // 색상 팔레트, 컬럼 레이아웃 CSS 파일 경로 (기본값: 블로그/assets/css/notion.css)
+	// ExpandToggleHeaders 토글 제목을 접지 않고 일반 제목과 내용으로 펼칠지 여부
+	ExpandToggleHeaders bool
+	// ImageCaptionStyle 이미지 캡션 형식 (chirpy(기본값, 다음 줄의 _caption_) | figure)
+	ImageCaptionStyle string
+	// FlattenColumns 컬럼 레이아웃을 HTML 없이 위에서 아래로 이어 붙일지 여부 (HTML 을 제거하는 테마용)
+	FlattenColumns bool
+}
+
+var defaultStatuses = []string{"Published", "Archived"}
+
+// defaultPermalink Chirpy 테마의 포스트 permalink
+const defaultPermalink = "/posts/:title/"
+
+// Chirpy(kramdown + MathJax) 의 수식 구분자
+const (
+	defaultInlineMath = "$...$"
+	defaultBlockMath  = "$$...$$"
+)
+
+// defaultEmbedIncludes Chirpy 테마의 임베드 include (:id 는 동영상 ID, :src 는 파일 주소)
+// vimeo 는 Chirpy 에 include 가 없으므로 기본값이 없으며, 이 경우 iframe 으로 넣는다.
+var defaultEmbedIncludes = map[string]string{
+	"youtube": "{% include embed/youtube.html id=':id' %}",
+	"video":   "{% include embed/video.html src=':src' %}",
+	"audio":   "{% include embed/audio.html src=':src' %}",
+}
+
+// defaultCalloutPrompts 콜아웃 색상(배경색 포함)과 Chirpy prompt 클래스의 대응
+var defaultCalloutPrompts = map[string]string{
+	"blue":   "info",
+	"green":  "tip",
+	"yellow": "warning",
+	"orange": "warning",
+	"red":    "danger",
+}
+
+const imageCaptionFigure = "figure"
+
+// currentRoot 현재 내보내고 있는 루트 (HandleRoots 에서 설정)
+var currentRoot Root
+
+// HandleRoot 하나의 루트의 모든 글을 내보냅니다.
+func HandleRoot(root Root) (*Report, error) {
+	return HandleRoots([]Root{root}, nil)
+}
+
+// HandleRoots 루트들을 순서대로 내보냅니다.
+// 글 사이의 링크(하위 페이지, 페이지 멘션)를 위해 모든 루트의 글을 먼저 등록한 뒤 변환합니다.
+// 글마다 결과(저장한 파일, 이미지, 경고)를 report 에 남기며, 글 하나를 내보내지 못하면(이미지 내려받기 실패 포함)
+// report 에 실패로 기록하고 나머지 글은 계속 내보냅니다.
+// 루트의 글 목록을 가져오지 못하는 등 동기화를 계속할 수 없는 경우에만 err 를 반환합니다.
+// previous 는 이전 동기화의 manifest 로, 그 이후 바뀌지 않은 글은 변환하지 않습니다. (nil 이면 모든 글을 변환)
+// 다음 동기화에 사용할 manifest 는 report.Manifest 로 반환합니다.
+func HandleRoots(roots []Root, previous *Manifest) (report *Report, err error) {
+	resetReport()
+	resetManifest(previous)
+	resetDependencies()
+
+	var failures []*PageError
+	rootPages := make([][]Page, len(roots))
+	includeSubPages := make([]bool, len(roots))
+
+	for i, root := range roots {
+		currentRoot = root
+		var rootFailures []*PageError
+		if rootPages[i], includeSubPages[i], rootFailures, err = root.collectPages(); err != nil {
+			return nil, fmt.Errorf("root %s: %w", root.ID, err)
+		}
+		if len(rootFailures) > 0 {
+			discoveryFailed(root.ID)
+		}
+		for _, failure := range rootFailures {
+			failure.Root = root.ID
+			reportFailure(failure)
+		}
+		failures = append(failures, rootFailures...)
+		for j := range rootPages[i] {
+			registerPage(&rootPages[i][j])
+		}
+	}
+
+	// 루트별 설정(currentRoot)을 사용하므로 루트는 하나씩 처리하며,
+	// 실패를 모으는 goroutine 이 끝난 뒤에 다음 루트로 넘어간다.
+	for i, root := range roots {
+		currentRoot = root
+		failures = append(failures, exportRootPages(root, rootPages[i], includeSubPages[i])...)
+
+		if cssErr := root.writeStylesheet(); cssErr != nil && err == nil {
+			err = fmt.Errorf("root %s: %w", root.ID, cssErr)
+		}
+	}
+
+	for _, failure := range failures {
+		log.Printf("❌ %v", failure)
+	}
+
+	report = buildReport(roots, failures)
+	report.Manifest, report.Removed = buildManifest(roots, report)
+
+	return report, err
+}
+
+// exportRootPages 루트의 글들을 동시에 내보내고, 글 변환과 이미지 내려받기의 실패를 모아 반환합니다.
+func exportRootPages(root Root, pages []Page, includeSubPages bool) []*PageError {
+	var wg sync.WaitGroup
+	errCh := make(chan error)
+	collected := make(chan []*PageError)
+	go func() {
+		var pageFailures []*PageError
+		for err := range errCh {
+			failure := asPageError(err, root.ID)
+			reportFailure(failure)
+			pageFailures = append(pageFailures, failure)
+		}
+		collected <- pageFailures
+	}()
+
+	for _, page := range pages {
+		wg.Add(1)
+		go func(page Page) {
+			defer wg.Done()
+			defer recoverPage(page, errCh)
+			if err := handlePage(page, includeSubPages, &wg, errCh); err != nil {
+				errCh <- &PageError{PageID: page.ID, Title: page.Title, Root: root.ID, Err: err}
+			}
+		}(page)
+	}
+	wg.Wait()
+
+	close(errCh)
+	return <-collected
+}
+
+// asPageError 실패를 PageError 로 바꾸고, 제목이 없으면 글 목록에서, 루트가 없으면 rootID 로 채웁니다. (이미지 내려받기 실패 등)
+func asPageError(err error, rootID string) *PageError {
+	var pageErr *PageError
+	if !errors.As(err, &pageErr) {
+		return &PageError{Root: rootID, Err: err}
+	}
+	if pageErr.Root == "" {
+		pageErr.Root = rootID
+	}
+	if pageErr.Title == "" {
+		if page, ok := lookupPage(pageErr.PageID); ok {
+			pageErr.Title = page.Title
+		}
+	}
+	return pageErr
+}
+
+// writeStylesheet class 방식의 색상과 컬럼 레이아웃에 필요한 CSS 를 저장합니다. (필요 없는 경우 저장하지 않음)
+func (r Root) writeStylesheet() error {
+	var css string
+	if r.colorStyle() == markdown.ColorStyleClass {
+		css += markdown.ColorCSS()
+	}
+	if !r.FlattenColumns {
+		css += markdown.ColumnsCSS()
+	}
+	if css == "" {
+		return nil
+	}
+
+	cssPath := r.stylesheetPath()
+	if err := os.MkdirAll(filepath.Dir(cssPath), os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(cssPath, []byte("/* Chan 이 생성한 파일입니다. (수정하지 마세요) */\n"+css), 0644); err != nil {
+		return err
+	}
+
+	log.Printf("🎨 Stylesheet saved: %s", cssPath)
+	return nil
+}
+
+// collectPages 루트 블록의 type 에 따라 collection view 의 글들 혹은 일반 페이지를 가져옵니다.
+// 글 안의 하위 페이지는 항상 내보내며, 일반 페이지 루트인 경우에만 IncludeSubPages 설정을 따릅니다.
+// 속성을 읽지 못한 데이터베이스 글은 failures 로 반환합니다.
+func (r Root) collectPages() (pages []Page, includeSubPages bool, failures []*PageError, err error) {
+	rootType, err := getRootType(r.ID)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	switch rootType {
+	case "page":
+		page, err := getTreeRootPage(r.ID)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		return []Page{page}, r.IncludeSubPages, nil, nil
+	default:
+		pages, failures, err = getCollectionViewPages(r.ID)
+		return pages, true, failures, err
+	}
+}
+
+// shouldExport 블로그로 내보낼 상태의 페이지인지 확인합니다.
+func (r Root) shouldExport(page Page) bool {
+	statuses := r.Statuses
+	if len(statuses) == 0 {
+		statuses = defaultStatuses
+	}
+
+	for _, status := range statuses {
+		if page.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// configHash 글의 변환 결과에 영향을 주는 설정(수식 구분자, 이미지 캡션 형식, 머리말 등)의 해시
+// manifest 에 글마다 기록해, 설정이 바뀌면 바뀌지 않은 글도 다시 변환한다.
+func (r Root) configHash() string {
+	render := r
+	render.ID, render.Statuses, render.Stylesheet = "", nil, "" // 글 내용과 관계없는 설정
+	data, _ := json.Marshal(render)                             // map 은 키 순서대로 직렬화된다.
+	return contentHash(string(data))
+}
+
+// applyFrontMatter 루트의 머리말 기본값을 페이지에 적용합니다.
+// author 는 덮어쓰고, categories/tags 는 페이지에 값이 없을 때만 사용하며, 나머지 키는 그대로 머리말에 추가합니다.
+func (r Root) applyFrontMatter(page *Page) {
+	for key, value := range r.FrontMatter {
+		switch key {
+		case "author":
+			page.Author = value
+		case "categories":
+			if len(page.Categories) == 0 {
+				page.Categories = strings.Split(value, ",")
+			}
+		case "tags":
+			if len(page.Tags) == 0 {
+				page.Tags = strings.Split(value, ",")
+			}
+		default:
+			if page.FrontMatter == nil {
+				page.FrontMatter = make(map[string]string)
+			}
+			page.FrontMatter[key] = value
+		}
+	}
+}
+
+// permalink 루트의 permalink 형식으로 글의 URL 을 만듭니다. (:title, :year, :month, :day 지원)
+func (r Root) permalink(page Page) string {
+	permalink := r.Permalink
+	if permalink == "" {
+		permalink = defaultPermalink
+	}
+
+	return strings.NewReplacer(
+		":title", postSlug(page),
+		":year", page.Published.Format("2006"),
+		":month", page.Published.Format("01"),
+		":day", page.Published.Format("02"),
+	).Replace(permalink)
+}
+
+// imageURL 블로그에서 이미지를 참조할 URL 경로입니다.
+// 블로그 저장소(PostDir 의 상위 폴더) 기준 ImgDir 의 상대 경로를 사용합니다. (예: /assets/pages)
+func (r Root) imageURL() string {
+	rel, err := filepath.Rel(filepath.Dir(r.PostDir), r.ImgDir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "/assets/pages"
+	}
+
+	return "/" + filepath.ToSlash(rel)
+}
+
+// mathDelimiters 수식 구분자 설정("$...$" 등)을 여는 구분자와 닫는 구분자로 나눕니다.
+func (r Root) mathDelimiters(block bool) (open, close string) {
+	delimiters, fallback := r.InlineMath, defaultInlineMath
+	if block {
+		delimiters, fallback = r.BlockMath, defaultBlockMath
+	}
+
+	open, close, found := strings.Cut(delimiters, utils.MathPlaceholder)
+	if !found {
+		open, close, _ = strings.Cut(fallback, utils.MathPlaceholder)
+	}
+	return
+}
+
+// includeValueEscaper include 의 따옴표 값(src='...')을 닫거나 Liquid 문법으로 해석될 수 있는 문자를 퍼센트 인코딩한다.
+// Liquid 문자열에는 이스케이프가 없으므로, 주소에서 같은 뜻인 퍼센트 인코딩을 사용한다.
+var includeValueEscaper = strings.NewReplacer(
+	"'", "%27",
+	"\"", "%22",
+	"{", "%7B",
+	"}", "%7D",
+	" ", "%20",
+)
+
+// embedInclude 임베드 종류에 맞는 테마의 include 를 만듭니다. 설정이 없거나 빈 값이면 빈 문자열을 반환합니다.
+func (r Root) embedInclude(kind, value string) string {
+	include, ok := r.EmbedIncludes[kind]
+	if !ok {
+		include = defaultEmbedIncludes[kind]
+	}
+
+	value = includeValueEscaper.Replace(value)
+	return strings.NewReplacer(":id", value, ":src", value).Replace(include)
+}
+
+// calloutPrompt 콜아웃 색상에 맞는 prompt 를 반환합니다. "blue_background" 는 "blue" 와 같이 취급하며, 대응이 없으면 빈 문자열입니다.
+func (r Root) calloutPrompt(color string) string {
+	color = strings.TrimSuffix(color, "_background")
+	if prompt, ok := r.CalloutPrompts[color]; ok {
+		return prompt
+	}
+
+	return defaultCalloutPrompts[color]
+}
+
+func (r Root) colorStyle() string {
+	if r.ColorStyle == "" {
+		return markdown.ColorStyleClass
+	}
+	return r.ColorStyle
+}
+
+// stylesheetPath CSS 파일 경로입니다. 기본값은 블로그 저장소(PostDir 의 상위 폴더)의 assets/css/notion.css 입니다.
+func (r Root) stylesheetPath() string {
+	if r.Stylesheet != "" {
+		return r.Stylesheet
+	}
+	return filepath.Join(filepath.Dir(r.PostDir), "assets", "css", "notion.css")
+}
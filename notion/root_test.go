@@ -0,0 +1,66 @@
+package notion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootShouldExportWithCustomStatuses(t *testing.T) {
+	// Arrange
+	defaultRoot := Root{}
+	tilRoot := Root{Statuses: []string{"Done"}}
+
+	// Act & Assert
+	assert.True(t, defaultRoot.shouldExport(Page{Status: "Published"}))
+	assert.True(t, defaultRoot.shouldExport(Page{Status: "Archived"}))
+	assert.False(t, defaultRoot.shouldExport(Page{Status: "Drafting"}))
+	assert.True(t, tilRoot.shouldExport(Page{Status: "Done"}))
+	assert.False(t, tilRoot.shouldExport(Page{Status: "Published"}))
+}
+
+func TestRootApplyFrontMatter(t *testing.T) {
+	// Arrange
+	root := Root{FrontMatter: map[string]string{
+		"author":     "til-bot",
+		"categories": "TIL",
+		"tags":       "default",
+		"layout":     "til",
+	}}
+	page := Page{Title: "오늘 배운 것", Author: defaultAuthor, Tags: []string{"go"}}
+
+	// Act
+	root.applyFrontMatter(&page)
+	meta := page.GetMetaString()
+
+	// Assert
+	assert.Equal(t, "til-bot", page.Author)
+	assert.Equal(t, []string{"TIL"}, page.Categories)
+	assert.Equal(t, []string{"go"}, page.Tags) // 페이지 값이 있으면 유지
+	assert.Contains(t, meta, "layout: til\n")
+}
+
+func TestRootImageURL(t *testing.T) {
+	// Arrange
+	posts := Root{PostDir: "/blog/_posts", ImgDir: "/blog/assets/pages"}
+	til := Root{PostDir: "/blog/_til", ImgDir: "/blog/assets/til"}
+	outside := Root{PostDir: "/blog/_posts", ImgDir: "/images"}
+
+	// Act & Assert
+	assert.Equal(t, "/assets/pages", posts.imageURL())
+	assert.Equal(t, "/assets/til", til.imageURL())
+	assert.Equal(t, "/assets/pages", outside.imageURL())
+}
+
+func TestRootEmbedIncludeEncodesQuotes(t *testing.T) {
+	// Arrange
+	root := Root{EmbedIncludes: map[string]string{"video": `{% include video.html src=":src" %}`}}
+
+	// Act
+	audio := root.embedInclude("audio", "https://example.com/it's {{ x }}.mp3")
+	video := root.embedInclude("video", `https://example.com/"a".mp4`)
+
+	// Assert
+	assert.Equal(t, "{% include embed/audio.html src='https://example.com/it%27s%20%7B%7B%20x%20%7D%7D.mp3' %}", audio)
+	assert.Equal(t, `{% include video.html src="https://example.com/%22a%22.mp4" %}`, video)
+}
@@ -0,0 +1,421 @@
+package notion
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const SourceSnapshot = "snapshot"
+
+// 스냅샷 디렉토리 구성
+//
+//	blocks.json  : 블록 ID → 블록 레코드
+//	pages.json   : 부모(collection) ID → 페이지 레코드 목록
+//	schemas.json : collection ID → 스키마
+//	users.json   : 멘션된 사용자 ID → 이름 (선택)
+//	views.json   : collection_view 블록 ID → 뷰 format (선택)
+const (
+	snapshotBlocksFile  = "blocks.json"
+	snapshotPagesFile   = "pages.json"
+	snapshotSchemasFile = "schemas.json"
+	snapshotUsersFile   = "users.json"
+	snapshotViewsFile   = "views.json"
+)
+
+// snapshotBlock 은 블록 레코드의 JSON 표현입니다.
+// content, properties, format 은 문자열이 아닌 JSON 그대로 저장하여 diff 로 확인하기 쉽게 합니다.
+type snapshotBlock struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	CollectionID   string          `json:"collection_id,omitempty"`
+	Content        json.RawMessage `json:"content,omitempty"`
+	Properties     json.RawMessage `json:"properties,omitempty"`
+	Format         json.RawMessage `json:"format,omitempty"`
+	CreatedTime    int64           `json:"created_time,omitempty"`
+	LastEditedTime int64           `json:"last_edited_time,omitempty"`
+}
+
+type snapshotPage struct {
+	ID         string          `json:"id"`
+	Properties json.RawMessage `json:"properties"`
+}
+
+func toRawJSON(s sql.NullString) json.RawMessage {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	return json.RawMessage(s.String)
+}
+
+func fromRawJSON(raw json.RawMessage) sql.NullString {
+	if len(raw) == 0 {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(raw), Valid: true}
+}
+
+/////////////////////
+// Snapshot Source //
+/////////////////////
+
+// snapshotSource 는 WriteSnapshot 으로 기록한 JSON 덤프 디렉토리를 읽는 Source 입니다.
+// notion.db 없이 같은 입력으로 변환 파이프라인을 재현하기 위해 사용합니다.
+type snapshotSource struct {
+	blocks  map[string]snapshotBlock
+	pages   map[string][]snapshotPage
+	schemas map[string]json.RawMessage
+	users   map[string]string
+	views   map[string]json.RawMessage
+}
+
+// NewSnapshotSource 스냅샷 디렉토리를 읽어 Source 를 생성합니다.
+func NewSnapshotSource(dir string) (Source, error) {
+	s := &snapshotSource{}
+
+	files := map[string]interface{}{
+		snapshotBlocksFile:  &s.blocks,
+		snapshotPagesFile:   &s.pages,
+		snapshotSchemasFile: &s.schemas,
+		snapshotUsersFile:   &s.users,
+		snapshotViewsFile:   &s.views,
+	}
+	for name, out := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if (name == snapshotUsersFile || name == snapshotViewsFile) && os.IsNotExist(err) {
+			continue // 해당 파일이 추가되기 전의 스냅샷
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, out); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *snapshotSource) Close() error {
+	return nil
+}
+
+func (s *snapshotSource) RootType(rootID string) (string, error) {
+	return s.blocks[rootID].Type, nil
+}
+
+func (s *snapshotSource) CollectionID(rootID string) (string, error) {
+	return s.blocks[rootID].CollectionID, nil
+}
+
+func (s *snapshotSource) CollectionSchema(collectionID string) (string, error) {
+	rawSchema, ok := s.schemas[collectionID]
+	if !ok {
+		return "", fmt.Errorf("schema not found in snapshot: %s", collectionID)
+	}
+	return string(rawSchema), nil
+}
+
+func (s *snapshotSource) CollectionViewFormat(blockID string) (string, error) {
+	return string(s.views[blockID]), nil
+}
+
+func (s *snapshotSource) Pages(parentID string) (pages []PageRecord, err error) {
+	for _, page := range s.pages[parentID] {
+		pages = append(pages, PageRecord{ID: page.ID, Properties: string(page.Properties)})
+	}
+	return
+}
+
+func (s *snapshotSource) Block(blockID string) (Block, error) {
+	record, ok := s.blocks[blockID]
+	if !ok {
+		// notion.db 와 동일하게, 존재하지 않는 블록은 빈 블록으로 취급합니다.
+		return Block{}, nil
+	}
+
+	return Block{
+		ID:             record.ID,
+		Type:           record.Type,
+		Content:        fromRawJSON(record.Content),
+		Properties:     fromRawJSON(record.Properties),
+		Format:         fromRawJSON(record.Format),
+		CreatedTime:    record.CreatedTime,
+		LastEditedTime: record.LastEditedTime,
+	}, nil
+}
+
+func (s *snapshotSource) UserName(userID string) (string, error) {
+	return s.users[userID], nil
+}
+
+/////////////////////
+// Snapshot Writer //
+/////////////////////
+
+type snapshotWriter struct {
+	src     Source
+	blocks  map[string]snapshotBlock
+	pages   map[string][]snapshotPage
+	schemas map[string]json.RawMessage
+	users   map[string]string
+	views   map[string]json.RawMessage
+
+	mentioned map[string]bool // 멘션 때문에 하위 블록 없이 기록된 블록
+}
+
+// WriteSnapshot 루트들로부터 내보내질 페이지, 블록, 스키마를 src 에서 읽어 dir 에 JSON 으로 기록합니다.
+// 루트에서 내보내지 않는 상태(Drafting 등)의 페이지는 기록하지 않습니다.
+func WriteSnapshot(src Source, roots []Root, dir string) error {
+	w := &snapshotWriter{
+		src:     src,
+		blocks:  make(map[string]snapshotBlock),
+		pages:   make(map[string][]snapshotPage),
+		schemas: make(map[string]json.RawMessage),
+		users:   make(map[string]string),
+		views:   make(map[string]json.RawMessage),
+
+		mentioned: make(map[string]bool),
+	}
+
+	for _, root := range roots {
+		if err := w.writeRoot(root); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	files := map[string]interface{}{
+		snapshotBlocksFile:  w.blocks,
+		snapshotPagesFile:   w.pages,
+		snapshotSchemasFile: w.schemas,
+		snapshotUsersFile:   w.users,
+		snapshotViewsFile:   w.views,
+	}
+	for name, v := range files {
+		// map 은 키 순서로 직렬화되므로 같은 입력이면 항상 같은 파일이 만들어집니다.
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(filepath.Join(dir, name), append(data, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *snapshotWriter) writeRoot(root Root) error {
+	rootID := root.ID
+
+	rootType, err := w.src.RootType(rootID)
+	if err != nil {
+		return err
+	}
+
+	switch rootType {
+	case "page":
+		// 페이지 트리는 루트 페이지 아래의 모든 블록(하위 페이지 포함)을 기록한다.
+		return w.writeBlock(rootID)
+	case "collection_view":
+	default:
+		return fmt.Errorf("block type is not same with exec type: %s", rootType)
+	}
+
+	// 루트 데이터베이스는 내보낼 상태의 글만, 본문 블록까지 기록한다.
+	collectionID, err := w.writeCollection(rootID, root.shouldExport)
+	if err != nil {
+		return err
+	}
+	if err = w.writeBlock(rootID); err != nil {
+		return err
+	}
+
+	for _, page := range w.pages[collectionID] {
+		if err = w.writeBlock(page.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeCollection collection_view 블록이 가리키는 collection 의 스키마와 페이지 레코드를 기록합니다.
+// shouldExport 가 nil 이면 모든 페이지를 기록합니다. (본문에 포함된 인라인 데이터베이스)
+func (w *snapshotWriter) writeCollection(blockID string, shouldExport func(Page) bool) (string, error) {
+	collectionID, err := w.src.CollectionID(blockID)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := w.schemas[collectionID]; ok {
+		return collectionID, nil
+	}
+
+	rawSchema, err := w.src.CollectionSchema(collectionID)
+	if err != nil {
+		return "", err
+	}
+	var schema map[string]Schema
+	if err = json.Unmarshal([]byte(rawSchema), &schema); err != nil {
+		return "", err
+	}
+	w.schemas[collectionID] = json.RawMessage(rawSchema)
+
+	records, err := w.src.Pages(collectionID)
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+
+	for _, record := range records {
+		if shouldExport != nil {
+			// 속성을 읽지 못한 글은 내보내지 않을 상태임을 알 수 없다면 기록해, 동기화에서 실패로 알린다.
+			page := Page{ID: record.ID}
+			if err = parsePageProperties(&page, record.Properties, schema); err != nil {
+				if status, ok := parsePageStatus(record.Properties, schema); ok && !shouldExport(Page{ID: record.ID, Status: status}) {
+					continue
+				}
+			} else if !shouldExport(page) {
+				continue
+			}
+		}
+
+		w.pages[collectionID] = append(w.pages[collectionID], snapshotPage{ID: record.ID, Properties: json.RawMessage(record.Properties)})
+		if err = w.writeMentions(record.Properties); err != nil {
+			return "", err
+		}
+	}
+
+	return collectionID, nil
+}
+
+// writeBlock 블록과 모든 하위 블록을 기록합니다.
+func (w *snapshotWriter) writeBlock(blockID string) error {
+	if _, ok := w.blocks[blockID]; ok && !w.mentioned[blockID] {
+		return nil
+	}
+	delete(w.mentioned, blockID)
+
+	block, err := w.src.Block(blockID)
+	if err != nil {
+		return err
+	}
+	if block.ID == "" {
+		return nil
+	}
+
+	w.blocks[blockID] = snapshotBlock{
+		ID:             block.ID,
+		Type:           block.Type,
+		Content:        toRawJSON(block.Content),
+		Properties:     toRawJSON(block.Properties),
+		Format:         toRawJSON(block.Format),
+		CreatedTime:    block.CreatedTime,
+		LastEditedTime: block.LastEditedTime,
+	}
+
+	if err = w.writeMentions(block.Properties.String); err != nil {
+		return err
+	}
+
+	if block.Type == "collection_view" {
+		snapshot := w.blocks[blockID]
+		if snapshot.CollectionID, err = w.writeCollection(blockID, nil); err != nil {
+			return err
+		}
+		w.blocks[blockID] = snapshot
+
+		rawFormat, err := w.src.CollectionViewFormat(blockID)
+		if err != nil {
+			return err
+		}
+		if rawFormat != "" {
+			w.views[blockID] = json.RawMessage(rawFormat)
+		}
+	}
+
+	if block.Type == "alias" {
+		// 링크한 페이지의 제목과 아이콘
+		format, _ := decodeFormat(block.ID, block.Format.String) // 잘못된 format 은 글을 변환할 때 알린다.
+		if targetID := format.AliasPointer.ID; targetID != "" {
+			if err = w.writeShallowBlock(targetID); err != nil {
+				return err
+			}
+		}
+	}
+
+	childIDs, err := extractChildIDs(block.Content)
+	if err != nil {
+		return err
+	}
+	if block.Type == "transclusion_reference" {
+		// 동기화 블록 사본은 원본 블록(다른 페이지에 있을 수 있음)이 있어야 변환할 수 있다.
+		format, _ := decodeFormat(block.ID, block.Format.String) // 잘못된 format 은 글을 변환할 때 알린다.
+		if originalID := format.TransclusionPointer.ID; originalID != "" {
+			childIDs = append(childIDs, originalID)
+		}
+	}
+	for _, childID := range childIDs {
+		if err = w.writeBlock(childID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeMentions 멘션된 사용자의 이름과, 멘션된 페이지의 블록(제목 표시용, 하위 블록 제외)을 기록합니다.
+func (w *snapshotWriter) writeMentions(properties string) error {
+	pageIDs, userIDs := collectMentions(properties)
+
+	for _, userID := range userIDs {
+		if _, ok := w.users[userID]; ok {
+			continue
+		}
+		name, err := w.src.UserName(userID)
+		if err != nil {
+			return err
+		}
+		w.users[userID] = name
+	}
+
+	for _, pageID := range pageIDs {
+		if err := w.writeShallowBlock(pageID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeShallowBlock 제목과 아이콘 표시에 필요한 블록만 하위 블록 없이 기록합니다.
+func (w *snapshotWriter) writeShallowBlock(blockID string) error {
+	if _, ok := w.blocks[blockID]; ok {
+		return nil
+	}
+	block, err := w.src.Block(blockID)
+	if err != nil {
+		return err
+	}
+	if block.ID == "" {
+		return nil
+	}
+	w.blocks[blockID] = snapshotBlock{
+		ID:             block.ID,
+		Type:           block.Type,
+		Properties:     toRawJSON(block.Properties),
+		Format:         toRawJSON(block.Format),
+		CreatedTime:    block.CreatedTime,
+		LastEditedTime: block.LastEditedTime,
+	}
+	w.mentioned[blockID] = true
+
+	return nil
+}
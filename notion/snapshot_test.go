@@ -0,0 +1,78 @@
+package notion
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	// Arrange
+	server := newTestNotionAPI(t, testNotionRoutes())
+	snapshotDir := t.TempDir()
+
+	require.NoError(t, WriteSnapshot(NewAPISource(server.URL, "secret_test"), []Root{{ID: testDatabaseID}}, snapshotDir))
+	firstBlocks, err := os.ReadFile(filepath.Join(snapshotDir, snapshotBlocksFile))
+	require.NoError(t, err)
+
+	// Act
+	src, err := NewSnapshotSource(snapshotDir)
+	require.NoError(t, err)
+
+	postDir := t.TempDir()
+	Init("secret_test", src)
+
+	report, err := HandleRoot(Root{ID: testDatabaseID, PostDir: postDir, ImgDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.Empty(t, report.Failures)
+
+	// 스냅샷에서 다시 기록해도 같은 파일이 만들어져야 함
+	rewriteDir := t.TempDir()
+	require.NoError(t, WriteSnapshot(src, []Root{{ID: testDatabaseID}}, rewriteDir))
+	secondBlocks, err := os.ReadFile(filepath.Join(rewriteDir, snapshotBlocksFile))
+	require.NoError(t, err)
+
+	// Assert
+	output, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-fromapi.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(output), `<h1 id="intro">Intro</h1>`)
+	assert.Contains(t, string(output), "Hello [**world**](https://example.com)")
+	assert.Equal(t, string(firstBlocks), string(secondBlocks))
+}
+
+func TestSnapshotDoesNotFetchFiles(t *testing.T) {
+	// Arrange
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC).UnixMilli()
+	src := &snapshotSource{blocks: map[string]snapshotBlock{
+		"root":    {ID: "root", Type: "page", Content: json.RawMessage(`["img", "missing", "pdf"]`), Properties: json.RawMessage(`{"title":[["Gallery"]]}`), CreatedTime: created},
+		"img":     {ID: "img", Type: "image"},
+		"missing": {ID: "missing", Type: "image"},
+		"pdf":     {ID: "pdf", Type: "pdf", Properties: json.RawMessage(`{"source":[["attachment:abc:slides.pdf"]]}`)},
+	}}
+	imgDir := t.TempDir()
+	for _, name := range []string{"img.png", "pdf.pdf"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(imgDir, "root"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(imgDir, "root", name), []byte("file"), 0644))
+	}
+
+	apiBaseURL := ApiBaseURL
+	ApiBaseURL = "http://127.0.0.1:1" // 공개 API 에 연결할 수 없음
+	t.Cleanup(func() { ApiBaseURL = apiBaseURL })
+	Init("", src)
+
+	// Act
+	report, err := HandleRoot(Root{ID: "root", PostDir: t.TempDir(), ImgDir: imgDir})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, report.Failures)
+	require.Len(t, report.Pages, 1)
+	assert.Equal(t, 2, report.Pages[0].ImagesSkipped)
+	assert.Zero(t, report.Pages[0].ImagesDownloaded)
+	assert.Equal(t, []string{"image missing is not downloaded (snapshot source does not fetch files)"}, report.Pages[0].Warnings)
+}
@@ -0,0 +1,160 @@
+package notion
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+)
+
+// Source 는 페이지, 블록, 스키마를 가져오는 저장소 추상화입니다.
+// 기본 구현은 Notion 데스크톱 앱의 notion.db(SQLite) 이며,
+// 값의 형태(properties, format, content JSON)는 notion.db 의 레코드 형식을 따릅니다.
+type Source interface {
+	// RootType 블록의 type 을 반환합니다. (예: collection_view, page)
+	RootType(rootID string) (string, error)
+	// CollectionID collection_view 블록이 가리키는 collection 의 ID 를 반환합니다.
+	CollectionID(rootID string) (string, error)
+	// CollectionSchema collection 의 스키마를 원본 JSON 문자열로 반환합니다.
+	CollectionSchema(collectionID string) (string, error)
+	// CollectionViewFormat collection_view 블록의 첫 번째 뷰의 format 을 원본 JSON 문자열로 반환합니다. (뷰가 없으면 빈 문자열)
+	CollectionViewFormat(blockID string) (string, error)
+	// Pages 부모 아래의 살아있는(템플릿 제외) 페이지 레코드를 반환합니다.
+	Pages(parentID string) ([]PageRecord, error)
+	// Block 단일 블록 레코드를 반환합니다.
+	Block(blockID string) (Block, error)
+	// UserName 노션 사용자의 이름을 반환합니다. (사용자 멘션에 사용)
+	UserName(userID string) (string, error)
+	Close() error
+}
+
+// PageRecord 는 properties 가 파싱되기 전의 페이지 레코드입니다.
+type PageRecord struct {
+	ID         string
+	Properties string
+}
+
+const (
+	SourceSQLite = "sqlite"
+	SourceAPI    = "api"
+)
+
+var errMoreThanOneRow = errors.New("more than one row returned")
+
+////////////////////
+// SQLite Source  //
+////////////////////
+
+type sqliteSource struct {
+	db *sql.DB
+}
+
+// NewSQLiteSource notion.db 를 읽는 Source 를 생성합니다.
+func NewSQLiteSource(dbPath string) (Source, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteSource{db: db}, nil
+}
+
+func (s *sqliteSource) Close() error {
+	return s.db.Close()
+}
+
+// queryOne 단일 행 조회 결과를 scan 하며, 여러 행이 반환되면 에러를 반환합니다.
+func (s *sqliteSource) queryOne(query string, args []interface{}, dest ...interface{}) error {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return err
+		}
+
+		if rows.Next() {
+			return errMoreThanOneRow
+		}
+	}
+
+	return rows.Err()
+}
+
+func (s *sqliteSource) RootType(rootID string) (t string, err error) {
+	query := "SELECT type FROM block WHERE id = ?"
+	log.Printf("Executing query: %s, with rootID: %s", query, rootID)
+	err = s.queryOne(query, []interface{}{rootID}, &t)
+	return
+}
+
+func (s *sqliteSource) CollectionID(rootID string) (colId string, err error) {
+	query := "SELECT collection_id FROM block WHERE id = ? AND type = 'collection_view'"
+	log.Printf("Executing query: %s, with rootID: %s", query, rootID)
+	err = s.queryOne(query, []interface{}{rootID}, &colId)
+	return
+}
+
+func (s *sqliteSource) CollectionSchema(collectionId string) (rawSchema string, err error) {
+	query := "SELECT schema FROM collection WHERE id = ?"
+	log.Printf("Executing query: %s, with collectionId: %s", query, collectionId)
+	err = s.queryOne(query, []interface{}{collectionId}, &rawSchema)
+	return
+}
+
+func (s *sqliteSource) CollectionViewFormat(blockID string) (rawFormat string, err error) {
+	var viewIDs sql.NullString
+	query := "SELECT view_ids FROM block WHERE id = ?"
+	if err = s.queryOne(query, []interface{}{blockID}, &viewIDs); err != nil || !viewIDs.Valid {
+		return
+	}
+
+	var ids []string
+	if err = json.Unmarshal([]byte(viewIDs.String), &ids); err != nil || len(ids) == 0 {
+		return
+	}
+
+	var format sql.NullString
+	query = "SELECT format FROM collection_view WHERE id = ?"
+	err = s.queryOne(query, []interface{}{ids[0]}, &format)
+	return format.String, err
+}
+
+func (s *sqliteSource) Pages(parentId string) (pages []PageRecord, err error) {
+	query := "SELECT id, properties FROM block WHERE parent_id = ? AND type = 'page' AND is_template IS NULL AND alive = 1"
+	log.Printf("Executing query: %s, with parentId: %s", query, parentId)
+	rows, err := s.db.Query(query, parentId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var page PageRecord
+		if err = rows.Scan(&page.ID, &page.Properties); err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+
+	return pages, rows.Err()
+}
+
+func (s *sqliteSource) Block(blockID string) (block Block, err error) {
+	var createdTime, lastEditedTime sql.NullFloat64
+
+	query := "SELECT id, type, content, properties, format, created_time, last_edited_time FROM block WHERE id = ?"
+	err = s.queryOne(query, []interface{}{blockID}, &block.ID, &block.Type, &block.Content, &block.Properties, &block.Format, &createdTime, &lastEditedTime)
+	block.CreatedTime = int64(createdTime.Float64)
+	block.LastEditedTime = int64(lastEditedTime.Float64)
+	return
+}
+
+func (s *sqliteSource) UserName(userID string) (name string, err error) {
+	query := "SELECT name FROM notion_user WHERE id = ?"
+	err = s.queryOne(query, []interface{}{userID}, &name)
+	return
+}
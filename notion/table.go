@@ -1,94 +1,151 @@
 package notion
 
 import (
-	"encoding/json"
 	"fmt"
-
 	"strings"
+
+	"github.com/shinychan95/Chan/markdown"
 )
 
 type Table struct {
 	ColumnOrder  []string
-	ColumnHeader bool
+	ColumnHeader bool              // 첫 번째 행이 제목 행인지 여부
+	RowHeader    bool              // 첫 번째 열이 제목 열인지 여부
+	ColumnColors map[string]string // 열 ID → 색상
 }
 
-func parseTable(tableBlock *Block) {
-	var format map[string]interface{}
-	if err := json.Unmarshal([]byte(tableBlock.Format.String), &format); err != nil {
-		panic(err)
-	}
-
-	columnOrder := format["table_block_column_order"].([]interface{})
-	columnHeader := format["table_block_column_header"].(bool)
-
-	columnOrderStr := make([]string, len(columnOrder))
-	for i, v := range columnOrder {
-		columnOrderStr[i] = v.(string)
+// parseTable 표의 format 에서 열 순서, 제목 행/열, 열 색상을 읽습니다.
+func parseTable(tableBlock *Block) error {
+	format, err := decodeFormat(tableBlock.ID, tableBlock.Format.String)
+	if err != nil {
+		return err
 	}
 
 	tableBlock.Table = &Table{
-		ColumnOrder:  columnOrderStr,
-		ColumnHeader: columnHeader,
+		ColumnOrder:  format.TableColumnOrder,
+		ColumnHeader: format.TableColumnHeader,
+		RowHeader:    format.TableRowHeader,
+		ColumnColors: make(map[string]string),
+	}
+	for colID, colFormat := range format.TableColumnFormat {
+		if colFormat.Color != "" && colFormat.Color != "default" {
+			tableBlock.Table.ColumnColors[colID] = colFormat.Color
+		}
 	}
+
+	return nil
 }
 
-func parseTableRow(properties string, columnOrder []string) []string {
-	var props map[string]interface{}
-	if properties != "" {
-		if err := json.Unmarshal([]byte(properties), &props); err != nil {
-			panic(err)
-		}
+// parseTableRow 행의 셀들을 열 순서대로 변환합니다. (properties: 열 ID → rich text)
+func parseTableRow(rowBlock Block, columnOrder []string) ([]string, error) {
+	props, err := decodeProperties(rowBlock.ID, rowBlock.Properties.String)
+	if err != nil {
+		return nil, err
 	}
 
 	var row []string
 	for _, colID := range columnOrder {
-		if cellData, ok := props[colID]; ok {
-			cell := ParseText(cellData)
-			row = append(row, cell)
-		} else {
-			row = append(row, "")
-		}
+		row = append(row, ParseText(props[colID]))
 	}
 
-	return row
+	return row, nil
 }
 
-func createTableMarkdown(tableBlock *Block, tableRowBlocks []Block) string {
-	defer func() {
-		if err := recover(); err != nil {
-			fmt.Println("패닉 복구:", err)
-		}
-	}()
+// tableCell 셀의 내용과 색상 (행 색상이 열 색상보다 우선)
+type tableCell struct {
+	Text  string
+	Color string
+}
 
-	parseTable(tableBlock)
+// createTableMarkdown 표를 마크다운 표(혹은 HTML 표)로 변환합니다. 행의 속성이나 format 이 잘못된 경우 오류를 반환한다.
+func createTableMarkdown(indent string, tableBlock *Block, tableRowBlocks []Block) (string, error) {
+	if err := parseTable(tableBlock); err != nil {
+		return "", err
+	}
+	table := tableBlock.Table
 
-	var markdown strings.Builder
+	if len(tableRowBlocks) == 0 || len(table.ColumnOrder) == 0 {
+		return "", nil
+	}
 
-	// 맨 첫 번째 행이 헤더가 되도록 설정
-	headerRow := tableRowBlocks[0]
-	headerCells := parseTableRow(headerRow.Properties.String, tableBlock.Table.ColumnOrder)
-	markdown.WriteString("| ")
-	for _, cell := range headerCells {
-		markdown.WriteString(cell + " | ")
+	// 제목 열이 있거나 여러 줄인 셀이 있으면 마크다운 표로 표현할 수 없으므로 HTML 표로 만든다.
+	useHTML := table.RowHeader || !table.ColumnHeader
+	rows := make([][]tableCell, len(tableRowBlocks))
+	for i, rowBlock := range tableRowBlocks {
+		rowFormat, err := decodeFormat(rowBlock.ID, rowBlock.Format.String)
+		if err != nil {
+			return "", err
+		}
+		cells, err := parseTableRow(rowBlock, table.ColumnOrder)
+		if err != nil {
+			return "", err
+		}
+		for j, text := range cells {
+			color := rowFormat.Color()
+			if color == "" {
+				color = table.ColumnColors[table.ColumnOrder[j]]
+			}
+			useHTML = useHTML || strings.Contains(text, "\n")
+			rows[i] = append(rows[i], tableCell{Text: text, Color: color})
+		}
+	}
+
+	if useHTML {
+		return createTableHTML(indent, table, rows), nil
+	}
+
+	var sb strings.Builder
+	writeRow := func(cells []tableCell) {
+		sb.WriteString(indent + "| ")
+		for _, cell := range cells {
+			sb.WriteString(markdown.Color(strings.ReplaceAll(cell.Text, "|", "\\|"), cell.Color, currentRoot.colorStyle()) + " | ")
+		}
+		sb.WriteString("\n")
 	}
-	markdown.WriteString("\n")
+
+	// 첫 번째 행이 제목 행
+	writeRow(rows[0])
 
 	// 헤더와 데이터 사이에 구분선 추가
-	markdown.WriteString("| ")
-	for range headerCells {
-		markdown.WriteString("--- | ")
+	sb.WriteString(indent + "| ")
+	for range rows[0] {
+		sb.WriteString("--- | ")
 	}
-	markdown.WriteString("\n")
+	sb.WriteString("\n")
 
 	// 데이터 행 작성
-	for _, rowBlock := range tableRowBlocks[1:] { // 첫 번째 행을 건너뛰고 시작
-		cells := parseTableRow(rowBlock.Properties.String, tableBlock.Table.ColumnOrder)
-		markdown.WriteString("| ")
-		for _, cell := range cells {
-			markdown.WriteString(cell + " | ")
+	for _, cells := range rows[1:] {
+		writeRow(cells)
+	}
+	sb.WriteString("\n")
+
+	return sb.String(), nil
+}
+
+// createTableHTML 제목 열, 여러 줄 셀 등을 표현하기 위한 HTML 표를 만듭니다.
+// markdown="span" 으로 셀 안의 마크다운 서식(굵게, 링크 등)은 kramdown 이 변환한다.
+func createTableHTML(indent string, table *Table, rows [][]tableCell) string {
+	var sb strings.Builder
+
+	sb.WriteString(indent + "<table class=\"notion-table\">\n")
+	for i, cells := range rows {
+		sb.WriteString(indent + "<tr>")
+		for j, cell := range cells {
+			tag, scope := "td", ""
+			switch {
+			case i == 0 && table.ColumnHeader:
+				tag, scope = "th", " scope=\"col\""
+			case j == 0 && table.RowHeader:
+				tag, scope = "th", " scope=\"row\""
+			}
+
+			text := strings.ReplaceAll(cell.Text, "\n", "<br/>")
+			attribute := markdown.ColorHTMLAttribute(cell.Color, currentRoot.colorStyle())
+			sb.WriteString(fmt.Sprintf("<%s%s%s markdown=\"span\">%s</%s>", tag, scope, attribute, text, tag))
 		}
-		markdown.WriteString("\n")
+		sb.WriteString("</tr>\n")
 	}
+	sb.WriteString(indent + "</table>\n\n")
 
-	return markdown.String()
+	return sb.String()
 }
@@ -0,0 +1,188 @@
+package notion
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shinychan95/Chan/markdown"
+)
+
+// getTreeRootPage 일반 페이지 루트의 글 정보를 만듭니다.
+// 하위 페이지들은 exportPage 에서 각각의 글로 내보내며, 글의 경로는 페이지 계층으로부터 만들어집니다.
+// (예: "Docs" > "Getting Started" → 2024-01-15-docs-getting-started.md)
+func getTreeRootPage(rootID string) (Page, error) {
+	rootBlock, err := getBlockData(rootID)
+	if err != nil {
+		return Page{}, err
+	}
+
+	return newTreePage(rootBlock, nil), nil
+}
+
+// exportPage 페이지를 글로 저장하고, includeSubPages 인 경우 본문의 하위 페이지들도 각각의 글로 내보냅니다.
+// 하위 페이지는 본문에서 링크 카드(혹은 link_to_page, breadcrumb)로 표시되므로,
+// 본문을 변환하기 전에 트리 전체의 하위 페이지 글 정보를 먼저 등록합니다.
+// 하위 페이지의 실패는 errCh 로 보내며, 상위 페이지가 실패해도 하위 페이지는 내보낸다.
+func exportPage(page Page, pageBlock Block, includeSubPages bool, wg *sync.WaitGroup, errCh chan error) error {
+	var subPages []treePage
+	if includeSubPages {
+		subPages = registerSubPages(page, pageBlock, errCh)
+	}
+
+	for _, subPage := range subPages {
+		wg.Add(1)
+		go func(subPage treePage) {
+			defer wg.Done()
+			defer recoverPage(subPage.page, errCh)
+			if err := writePage(subPage.page, subPage.block, wg, errCh); err != nil {
+				errCh <- &PageError{PageID: subPage.page.ID, Title: subPage.page.Title, Err: err}
+			}
+		}(subPage)
+	}
+
+	return writePage(page, pageBlock, wg, errCh)
+}
+
+// treePage 내보낼 하위 페이지와 파싱된 페이지 블록
+type treePage struct {
+	page  Page
+	block Block
+}
+
+// registerSubPages 본문의 하위 페이지들을 (그 하위 페이지들까지) 등록하고 파싱된 블록과 함께 반환합니다.
+// 블록을 가져오지 못한 하위 페이지는 등록하지 않으므로, 본문에서는 노션 링크로 남는다.
+func registerSubPages(parent Page, parentBlock Block, errCh chan error) (subPages []treePage) {
+	for _, subPageBlock := range collectSubPageBlocks(parentBlock.Children) {
+		subPage := newTreePage(subPageBlock, &parent)
+
+		block, err := loadPageBlock(subPage.ID)
+		if err != nil {
+			discoveryFailed(currentRoot.ID)
+			errCh <- &PageError{PageID: subPage.ID, Title: subPage.Title, Err: err}
+			continue
+		}
+		registerPage(&subPage)
+
+		subPages = append(subPages, treePage{page: subPage, block: block})
+		subPages = append(subPages, registerSubPages(subPage, block, errCh)...)
+	}
+	return subPages
+}
+
+// newTreePage 데이터베이스 속성이 없는 일반 페이지(혹은 글 안의 하위 페이지)의 메타 정보를 만듭니다.
+// 상위 페이지의 경로와 제목을 이어 경로와 카테고리로 사용하고, 발행일은 페이지 생성 시각을 사용합니다.
+func newTreePage(pageBlock Block, parent *Page) Page {
+	title := parsePlainTitle(pageBlock.Properties.String)
+	if title == "" {
+		title = "Untitled"
+	}
+
+	published := time.Now()
+	if pageBlock.CreatedTime > 0 {
+		published = time.UnixMilli(pageBlock.CreatedTime)
+		if location, err := time.LoadLocation("Asia/Seoul"); err == nil {
+			published = published.In(location)
+		}
+	}
+
+	page := Page{
+		ID:        pageBlock.ID,
+		Title:     title,
+		Status:    "Published",
+		Path:      title,
+		Author:    defaultAuthor,
+		Published: published,
+	}
+
+	if parent != nil {
+		page.ParentID = parent.ID
+		page.Path = parent.Path + "/" + title
+		page.Author = parent.Author
+		page.Categories = append(parent.Categories[:len(parent.Categories):len(parent.Categories)], parent.Title)
+		page.Tags = parent.Tags
+		page.FrontMatter = parent.FrontMatter
+		return page
+	}
+
+	currentRoot.applyFrontMatter(&page)
+
+	return page
+}
+
+// collectSubPageBlocks 블록 트리에서 하위 페이지 블록들을 찾습니다. (하위 페이지의 내부로는 내려가지 않음)
+func collectSubPageBlocks(blocks []Block) (subPages []Block) {
+	for _, block := range blocks {
+		if block.Type == "page" {
+			subPages = append(subPages, block)
+			continue
+		}
+		subPages = append(subPages, collectSubPageBlocks(block.Children)...)
+	}
+	return
+}
+
+// parsePlainTitle 서식 없이 title 속성의 텍스트만 이어 붙입니다.
+func parsePlainTitle(properties string) (title string) {
+	return parsePlainProperty(properties, "title")
+}
+
+// parsePlainProperty 서식을 제외한 속성의 텍스트를 가져옵니다. (예: 이미지 caption)
+func parsePlainProperty(properties, key string) string {
+	props, _ := decodeProperties("", properties)
+	return props.Plain(key)
+}
+
+// pageLinkCard 페이지 블록의 링크 카드. 내보내는 글이면 글 주소로, 아니면 노션 공개 페이지 주소로 링크합니다.
+func pageLinkCard(indent string, pageBlock Block) string {
+	title, url := parsePlainTitle(pageBlock.Properties.String), notionURL(pageBlock.ID)
+	if page, ok := lookupPage(pageBlock.ID); ok {
+		title, url = page.Title, page.URL
+	}
+	if title == "" {
+		title = "Untitled"
+	}
+
+	// 링크한 페이지의 format 이 잘못된 경우 아이콘 없이 링크한다. (글의 블록이라면 ParseBlock 에서 알린다)
+	format, _ := decodeFormat(pageBlock.ID, pageBlock.Format.String)
+	return markdown.PageLink(indent, format.Icon(), markdown.Escape(title), url)
+}
+
+// aliasLinkCard link_to_page 블록이 가리키는 페이지의 링크 카드
+// format 예: {"alias_pointer": {"id": "<page id>", "table": "block", "spaceId": "..."}}
+func aliasLinkCard(pageID, indent string, aliasBlock Block) string {
+	format, _ := decodeFormat(aliasBlock.ID, aliasBlock.Format.String) // 잘못된 format 은 ParseBlock 에서 알린다.
+	targetID := format.AliasPointer.ID
+	if targetID == "" {
+		warnPage(pageID, "link_to_page block %s has no target", aliasBlock.ID)
+		return ""
+	}
+
+	addDependency(pageID, blockDependency+targetID)
+	target, err := source.Block(targetID)
+	if err != nil {
+		warnPage(pageID, "cannot get linked page %s: %v", targetID, err)
+	}
+	target.ID = targetID
+
+	return pageLinkCard(indent, target)
+}
+
+// breadcrumbLinks 글의 상위 글들(하위 페이지로 내보낸 경우)과 현재 글의 제목을 순서대로 반환합니다.
+func breadcrumbLinks(pageID string) []string {
+	page, ok := lookupPage(pageID)
+	if !ok {
+		return nil
+	}
+
+	links := []string{markdown.Escape(page.Title)}
+	for visited := map[string]bool{page.ID: true}; page.ParentID != "" && !visited[page.ParentID]; {
+		if page, ok = lookupPage(page.ParentID); !ok {
+			break
+		}
+		visited[page.ID] = true
+		addDependency(pageID, blockDependency+page.ID)
+		links = append([]string{markdown.Link(markdown.Escape(page.Title), page.URL)}, links...)
+	}
+
+	return links
+}
@@ -0,0 +1,378 @@
+package notion
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPageTreeSource Docs > Getting Started > Install 구조의 페이지 트리를 가진 Source 를 만듭니다.
+func newTestPageTreeSource() Source {
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC).UnixMilli()
+	page := func(id, title string, children ...string) snapshotBlock {
+		content, _ := json.Marshal(children)
+		return snapshotBlock{
+			ID:          id,
+			Type:        "page",
+			Content:     content,
+			Properties:  json.RawMessage(`{"title":[["` + title + `"]]}`),
+			CreatedTime: created,
+		}
+	}
+	text := func(id, title string) snapshotBlock {
+		return snapshotBlock{ID: id, Type: "text", Properties: json.RawMessage(`{"title":[["` + title + `"]]}`)}
+	}
+
+	return &snapshotSource{blocks: map[string]snapshotBlock{
+		"root":    page("root", "Docs", "t1", "child"),
+		"t1":      text("t1", "문서 루트"),
+		"child":   page("child", "Getting Started", "t2", "install"),
+		"t2":      text("t2", "시작하기"),
+		"install": page("install", "Install", "t3"),
+		"t3":      text("t3", "설치 방법"),
+	}}
+}
+
+func TestHandlePageTreeWithSubPages(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	Init("secret_test", newTestPageTreeSource())
+
+	// Act
+	report, err := HandleRoot(Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true})
+	require.NoError(t, err)
+	assert.Empty(t, report.Failures)
+
+	// Assert
+	root, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(root), "title: Docs")
+	assert.Contains(t, string(root), "문서 루트")
+	assert.NotContains(t, string(root), "시작하기") // 하위 페이지 내용은 별도 글로
+	assert.Contains(t, string(root), "> 📄 [Getting Started](/posts/docs-getting-started/)")
+
+	install, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs-getting-started-install.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(install), "categories: [Docs, Getting Started]")
+	assert.Contains(t, string(install), "설치 방법")
+}
+
+func TestHandlePageTreeIsolatesPageFailure(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	src := newTestPageTreeSource().(*snapshotSource)
+	src.blocks["t2"] = snapshotBlock{ID: "t2", Type: "text", Properties: json.RawMessage(`{"title":"시작하기"}`)}
+	Init("secret_test", src)
+
+	// Act
+	report, err := HandleRoot(Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, "child", report.Failures[0].PageID)
+	assert.Equal(t, "Getting Started", report.Failures[0].Title)
+	var propErr *PropertyError
+	assert.ErrorAs(t, report.Failures[0], &propErr)
+	assert.Equal(t, "t2", propErr.BlockID)
+	assert.Equal(t, 2, report.Exported())
+
+	assert.NoFileExists(t, filepath.Join(postDir, "2024-01-15-docs-getting-started.md"))
+	assert.FileExists(t, filepath.Join(postDir, "2024-01-15-docs.md"))
+	assert.FileExists(t, filepath.Join(postDir, "2024-01-15-docs-getting-started-install.md"))
+}
+
+func TestHandleRootsReportsFailureInItsRoot(t *testing.T) {
+	// Arrange
+	src := newTestPageTreeSource().(*snapshotSource)
+	src.blocks["t2"] = snapshotBlock{ID: "t2", Type: "text", Properties: json.RawMessage(`{"title":"시작하기"}`)}
+	src.blocks["notes"] = snapshotBlock{ID: "notes", Type: "page", Content: json.RawMessage(`["t4"]`), Properties: json.RawMessage(`{"title":[["Notes"]]}`)}
+	src.blocks["t4"] = snapshotBlock{ID: "t4", Type: "text", Properties: json.RawMessage(`{"title":[["메모"]]}`)}
+	Init("secret_test", src)
+	roots := []Root{
+		{ID: "root", PostDir: t.TempDir(), ImgDir: t.TempDir(), IncludeSubPages: true},
+		{ID: "notes", PostDir: t.TempDir(), ImgDir: t.TempDir()},
+	}
+
+	// Act
+	report, err := HandleRoots(roots, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, "root", report.Failures[0].Root)
+
+	rootOf := make(map[string]string)
+	for _, pr := range report.Pages {
+		rootOf[pr.PageID] = pr.Root
+	}
+	assert.Equal(t, map[string]string{"root": "root", "child": "root", "install": "root", "notes": "notes"}, rootOf)
+}
+
+func TestHandleRootReport(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	src := newTestPageTreeSource().(*snapshotSource)
+	src.blocks["t3"] = snapshotBlock{ID: "t3", Type: "unknown_widget"}
+	Init("secret_test", src)
+
+	// Act
+	report, err := HandleRoot(Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true})
+	require.NoError(t, err)
+
+	// Assert
+	require.Len(t, report.Pages, 3)
+	docs, started, install := report.Pages[0], report.Pages[1], report.Pages[2]
+	assert.Equal(t, "Docs", docs.Title)
+	assert.Equal(t, "Getting Started", started.Title)
+	assert.Equal(t, "Install", install.Title)
+	for _, pr := range report.Pages {
+		assert.Equal(t, PageExported, pr.Status)
+		assert.Equal(t, "root", pr.Root)
+	}
+	assert.Equal(t, filepath.Join(postDir, "2024-01-15-docs-getting-started-install.md"), install.File)
+	assert.Equal(t, []string{"unsupported block type unknown_widget (block t3)"}, install.Warnings)
+	assert.Empty(t, docs.Warnings)
+	assert.Contains(t, report.String(), "✅ Install → "+install.File+"\n    ⚠️ unsupported block type unknown_widget (block t3)\n")
+}
+
+func TestHandleRootWithMissingRoot(t *testing.T) {
+	// Arrange
+	Init("secret_test", newTestPageTreeSource())
+
+	// Act
+	_, err := HandleRoot(Root{ID: "missing", PostDir: t.TempDir(), ImgDir: t.TempDir()})
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestHandlePageTreeWithoutSubPages(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	Init("secret_test", newTestPageTreeSource())
+
+	// Act
+	report, err := HandleRoot(Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.Empty(t, report.Failures)
+
+	// Assert
+	files, err := filepath.Glob(filepath.Join(postDir, "*.md"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(postDir, "2024-01-15-docs.md")}, files)
+
+	root, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(root), "> 📄 [Getting Started](https://www.notion.so/child)") // 내보내지 않은 하위 페이지는 노션 링크
+}
+
+func TestHandlePageWithSyncedBlocks(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	src := newTestPageTreeSource().(*snapshotSource)
+	reference := func(id, originalID string) snapshotBlock {
+		return snapshotBlock{ID: id, Type: "transclusion_reference", Format: json.RawMessage(`{"transclusion_reference_pointer":{"id":"` + originalID + `","table":"block"}}`)}
+	}
+	src.blocks["root"] = snapshotBlock{ID: "root", Type: "page", Content: json.RawMessage(`["bio","copy","loop"]`), Properties: json.RawMessage(`{"title":[["Docs"]]}`), CreatedTime: src.blocks["root"].CreatedTime}
+	src.blocks["bio"] = snapshotBlock{ID: "bio", Type: "transclusion_container", Content: json.RawMessage(`["bio-text","self"]`)}
+	src.blocks["bio-text"] = snapshotBlock{ID: "bio-text", Type: "text", Properties: json.RawMessage(`{"title":[["글쓴이 소개"]]}`)}
+	src.blocks["self"] = reference("self", "bio") // 원본 안에서 자기 자신을 참조
+	src.blocks["copy"] = reference("copy", "bio")
+	src.blocks["loop"] = reference("loop", "loop")
+	Init("secret_test", src)
+
+	// Act
+	report, err := HandleRoot(Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.Empty(t, report.Failures)
+
+	// Assert
+	output, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs.md"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(output), "글쓴이 소개"))
+}
+
+func TestHandlePageTreeWithLinkToPageAndBreadcrumb(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	src := newTestPageTreeSource().(*snapshotSource)
+	alias := func(id, targetID string) snapshotBlock {
+		return snapshotBlock{ID: id, Type: "alias", Format: json.RawMessage(`{"alias_pointer":{"id":"` + targetID + `","table":"block"}}`)}
+	}
+	src.blocks["root"] = snapshotBlock{ID: "root", Type: "page", Content: json.RawMessage(`["t1","child","to-install","to-other"]`), Properties: json.RawMessage(`{"title":[["Docs"]]}`), CreatedTime: src.blocks["root"].CreatedTime}
+	src.blocks["install"] = snapshotBlock{ID: "install", Type: "page", Content: json.RawMessage(`["crumb","t3"]`), Properties: json.RawMessage(`{"title":[["Install"]]}`), CreatedTime: src.blocks["root"].CreatedTime}
+	src.blocks["crumb"] = snapshotBlock{ID: "crumb", Type: "breadcrumb"}
+	src.blocks["to-install"] = alias("to-install", "install")
+	src.blocks["to-other"] = alias("to-other", "other")
+	src.blocks["other"] = snapshotBlock{ID: "other", Type: "page", Properties: json.RawMessage(`{"title":[["FAQ"]]}`), Format: json.RawMessage(`{"page_icon":"❓"}`)}
+	Init("secret_test", src)
+
+	// Act
+	report, err := HandleRoot(Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true})
+	require.NoError(t, err)
+	assert.Empty(t, report.Failures)
+
+	// Assert
+	root, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(root), "> 📄 [Install](/posts/docs-getting-started-install/)")
+	assert.Contains(t, string(root), "> ❓ [FAQ](https://www.notion.so/other)") // 내보내지 않은 페이지는 노션 링크
+
+	install, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs-getting-started-install.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(install), "[Docs](/posts/docs/) / [Getting Started](/posts/docs-getting-started/) / Install")
+}
+
+func TestHandlePageWithInlineDatabase(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	src := newTestPageTreeSource().(*snapshotSource)
+	src.blocks["root"] = snapshotBlock{ID: "root", Type: "page", Content: json.RawMessage(`["db"]`), Properties: json.RawMessage(`{"title":[["Docs"]]}`), CreatedTime: src.blocks["root"].CreatedTime}
+	src.blocks["db"] = snapshotBlock{ID: "db", Type: "collection_view", CollectionID: "col"}
+	src.schemas = map[string]json.RawMessage{"col": json.RawMessage(`{
+		"title": {"name": "Name", "type": "title"},
+		"lang": {"name": "Language", "type": "multi_select"},
+		"star": {"name": "Stars", "type": "number"},
+		"ok": {"name": "Stable", "type": "checkbox"},
+		"site": {"name": "Site", "type": "url"},
+		"date": {"name": "Released", "type": "date"},
+		"memo": {"name": "Memo", "type": "text"}
+	}`)}
+	src.views = map[string]json.RawMessage{"db": json.RawMessage(`{"table_properties": [
+		{"property": "title", "visible": true},
+		{"property": "memo", "visible": false},
+		{"property": "star", "visible": true},
+		{"property": "lang", "visible": true},
+		{"property": "ok", "visible": true},
+		{"property": "date", "visible": true},
+		{"property": "site", "visible": true}
+	]}`)}
+	src.pages = map[string][]snapshotPage{"col": {
+		{ID: "r2", Properties: json.RawMessage(`{"title":[["Jekyll | Chirpy"]],"lang":[["Ruby,Liquid"]],"memo":[["숨김"]]}`)}, // 행은 ID 순서로 쓴다.
+		{ID: "r1", Properties: json.RawMessage(`{"title":[["Hugo"]],"lang":[["Go"]],"star":[["70000"]],"ok":[["Yes"]],"date":[["‣",[["d",{"type":"date","start_date":"2013-07-04"}]]]],"site":[["https://gohugo.io"]]}`)},
+	}}
+	Init("secret_test", src)
+
+	// Act
+	report, err := HandleRoot(Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.Empty(t, report.Failures)
+
+	// Assert
+	output, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "| Name | Stars | Language | Stable | Released | Site | \n"+
+		"| --- | ---: | --- | --- | --- | --- | \n"+
+		"| Hugo | 70000 | Go | ✅ | 2013-07-04 | [https://gohugo.io](https://gohugo.io) | \n"+
+		"| Jekyll \\| Chirpy |  | Ruby, Liquid | ⬜ |  |  | \n")
+	assert.NotContains(t, string(output), "숨김")
+}
+
+func TestHandlePageWithEquations(t *testing.T) {
+	// Arrange
+	postDir := t.TempDir()
+	src := newTestPageTreeSource().(*snapshotSource)
+	src.blocks["t1"] = snapshotBlock{ID: "t1", Type: "text", Properties: json.RawMessage(`{"title":[["넓이는 "],["⁍",[["e","\\pi r^2"]]]]}`)}
+	src.blocks["t2"] = snapshotBlock{ID: "t2", Type: "equation", Properties: json.RawMessage(`{"title":[["e^{i\\pi} + 1 = 0"]]}`)}
+	Init("secret_test", src)
+
+	// Act
+	report, err := HandleRoot(Root{ID: "root", PostDir: postDir, ImgDir: t.TempDir(), IncludeSubPages: true, InlineMath: `\(...\)`, BlockMath: `\[...\]`})
+	require.NoError(t, err)
+	assert.Empty(t, report.Failures)
+
+	// Assert
+	root, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(root), `넓이는 \(\pi r^2\)`)
+	assert.Contains(t, string(root), "math: true\n")
+
+	child, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs-getting-started.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(child), "\\[\ne^{i\\pi} + 1 = 0\n\\]\n\n")
+
+	install, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs-getting-started-install.md"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(install), "math: true")
+}
+
+func TestHandlePageWithMediaBlocks(t *testing.T) {
+	// Arrange
+	server := newTestNotionAPI(t, map[string]string{
+		"GET /blocks/m5": `{"object":"block","id":"m5","type":"pdf","pdf":{"type":"file","file":{"url":"https://prod-files-secure.s3.us-west-2.amazonaws.com/x/slides.pdf?X-Amz-Expires=3600"}}}`,
+	})
+	defaultBaseURL := ApiBaseURL
+	ApiBaseURL = server.URL
+	t.Cleanup(func() { ApiBaseURL = defaultBaseURL })
+
+	blogDir := t.TempDir()
+	postDir, imgDir := filepath.Join(blogDir, "_posts"), filepath.Join(blogDir, "assets", "pages")
+	require.NoError(t, os.MkdirAll(filepath.Join(imgDir, "root"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(imgDir, "root", "m5.pdf"), []byte("%PDF"), 0644)) // 이미 내려받은 파일
+
+	media := func(id, blockType, source string) snapshotBlock {
+		return snapshotBlock{ID: id, Type: blockType, Properties: json.RawMessage(`{"source":[["` + source + `"]]}`)}
+	}
+	src := newTestPageTreeSource().(*snapshotSource)
+	src.blocks["root"] = snapshotBlock{ID: "root", Type: "page", Content: json.RawMessage(`["m1","m2","m3","m4","m5"]`), Properties: json.RawMessage(`{"title":[["Docs"]]}`), CreatedTime: src.blocks["root"].CreatedTime}
+	src.blocks["m1"] = media("m1", "video", "https://www.youtube.com/watch?v=dQw4w9WgXcQ")
+	src.blocks["m2"] = media("m2", "video", "https://vimeo.com/76979871")
+	src.blocks["m3"] = media("m3", "embed", "https://codepen.io/pen/abc")
+	src.blocks["m4"] = media("m4", "audio", "https://example.com/podcast.mp3")
+	src.blocks["m5"] = media("m5", "pdf", "attachment:1234:slides.pdf")
+	Init("secret_test", src)
+
+	// Act
+	report, err := HandleRoot(Root{ID: "root", PostDir: postDir, ImgDir: imgDir})
+	require.NoError(t, err)
+	assert.Empty(t, report.Failures)
+
+	// Assert
+	output, err := os.ReadFile(filepath.Join(postDir, "2024-01-15-docs.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "{% include embed/youtube.html id='dQw4w9WgXcQ' %}\n\n")
+	assert.Contains(t, string(output), `<iframe src="https://player.vimeo.com/video/76979871"`)
+	assert.Contains(t, string(output), `<iframe src="https://codepen.io/pen/abc"`)
+	assert.Contains(t, string(output), `sandbox="allow-scripts allow-same-origin allow-popups allow-presentation"`)
+	assert.Contains(t, string(output), "{% include embed/audio.html src='https://example.com/podcast.mp3' %}")
+	assert.Contains(t, string(output), `<object data="/assets/pages/root/m5.pdf" type="application/pdf" width="100%" height="600"><a href="/assets/pages/root/m5.pdf">slides.pdf</a></object>`)
+}
+
+func TestHandleCollectionRootIgnoresBrokenDrafts(t *testing.T) {
+	// Arrange
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC).UnixMilli()
+	src := &snapshotSource{
+		blocks: map[string]snapshotBlock{
+			"db":     {ID: "db", Type: "collection_view", CollectionID: "col"},
+			"good":   {ID: "good", Type: "page", CreatedTime: created},
+			"broken": {ID: "broken", Type: "page", CreatedTime: created},
+		},
+		schemas: map[string]json.RawMessage{"col": json.RawMessage(`{
+			"title": {"name": "Title", "type": "title"},
+			"status": {"name": "Status", "type": "select"},
+			"date": {"name": "Published", "type": "date"}
+		}`)},
+		pages: map[string][]snapshotPage{"col": {
+			{ID: "good", Properties: json.RawMessage(`{"title":[["Good"]],"status":[["Published"]]}`)},
+			{ID: "broken", Properties: json.RawMessage(`{"title":[["Broken"]],"status":[["Published"]],"date":[["not a date"]]}`)},
+			{ID: "draft", Properties: json.RawMessage(`{"title":[["Draft"]],"status":[["Drafting"]],"date":[["not a date"]]}`)},
+		}},
+	}
+	Init("secret_test", src)
+
+	// Act
+	report, err := HandleRoot(Root{ID: "db", PostDir: t.TempDir(), ImgDir: t.TempDir()})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, "broken", report.Failures[0].PageID)
+	assert.Equal(t, 1, report.Exported())
+}
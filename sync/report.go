@@ -0,0 +1,61 @@
+package sync
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/shinychan95/Chan/notion"
+)
+
+// syncReport 동기화 결과 파일(report_path)의 형식
+type syncReport struct {
+	Success    bool                 `json:"success"`
+	Message    string               `json:"message"`
+	Error      string               `json:"error,omitempty"`
+	PostCount  int                  `json:"post_count"`
+	ImageCount int                  `json:"image_count"`
+	Duration   string               `json:"duration"`
+	Timestamp  time.Time            `json:"timestamp"`
+	Pages      []*notion.PageReport `json:"pages"`
+	// 더 이상 내보내지 않아 지운 글 파일
+	Removed []string `json:"removed,omitempty"`
+	// 어떤 글도 사용하지 않아 지운(clean_images) 혹은 지우지 않은 이미지
+	RemovedImages  []string `json:"removed_images,omitempty"`
+	OrphanedImages []string `json:"orphaned_images,omitempty"`
+}
+
+// writeReport 동기화 결과를 글별 결과와 함께 JSON 으로 저장합니다. (이전 결과는 덮어씀)
+func writeReport(path string, result *SyncResult) error {
+	report := syncReport{
+		Success:    result.Success,
+		Message:    result.Message,
+		PostCount:  result.PostCount,
+		ImageCount: result.ImageCount,
+		Duration:   result.Duration.String(),
+		Timestamp:  result.Timestamp,
+		Pages:      []*notion.PageReport{},
+	}
+	if result.Error != nil {
+		report.Error = result.Error.Error()
+	}
+	if result.Report != nil {
+		if result.Report.Pages != nil {
+			report.Pages = result.Report.Pages
+		}
+		report.Removed = result.Report.Removed
+		report.RemovedImages = result.Report.RemovedImages
+		report.OrphanedImages = result.Report.OrphanedImages
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -18,6 +18,7 @@ type SyncResult struct {
 	Success    bool
 	Message    string
 	Error      error
+	Report     *notion.Report // 글별 처리 결과 (Notion 에서 글을 가져오기 전에 실패하면 nil)
 	PostCount  int
 	ImageCount int
 	Duration   time.Duration
@@ -87,6 +88,12 @@ func (bs *BlogSyncer) updateStatus(isRunning bool, progress string) {
 }
 
 func (bs *BlogSyncer) setResult(result *SyncResult) {
+	if bs.config.ReportPath != "" {
+		if err := writeReport(bs.config.ReportPath, result); err != nil {
+			log.Printf("Warning: could not write sync report %s: %v", bs.config.ReportPath, err)
+		}
+	}
+
 	bs.mutex.Lock()
 	defer bs.mutex.Unlock()
 	bs.status.LastResult = result
@@ -94,7 +101,9 @@ func (bs *BlogSyncer) setResult(result *SyncResult) {
 	bs.status.Progress = ""
 }
 
-func (bs *BlogSyncer) SyncToBlog() *SyncResult {
+// SyncToBlog Notion 의 글을 블로그로 내보내고 배포합니다.
+// 이전 동기화 이후 바뀐 글만 다시 변환하며, full 이면(혹은 이전 동기화의 manifest 가 없으면) 모든 글을 다시 변환합니다.
+func (bs *BlogSyncer) SyncToBlog(full bool) *SyncResult {
 	startTime := time.Now()
 
 	// 이미 실행 중인지 확인
@@ -110,7 +119,7 @@ func (bs *BlogSyncer) SyncToBlog() *SyncResult {
 	bs.updateStatus(true, "초기화 중...")
 
 	// UUID 검증
-	rootID, err := utils.CheckUUIDv4Format(bs.config.RootID)
+	roots, err := bs.roots()
 	if err != nil {
 		result := &SyncResult{
 			Success:   false,
@@ -125,16 +134,11 @@ func (bs *BlogSyncer) SyncToBlog() *SyncResult {
 
 	// Notion 초기화
 	bs.updateStatus(true, "Notion 연결 중...")
-	notion.Init(bs.config.ApiKey, bs.config.PostDir, bs.config.ImgDir, bs.config.DBPath)
-	defer notion.Close()
-
-	// 기존 포스트 삭제
-	bs.updateStatus(true, "기존 포스트 정리 중...")
-	err = bs.clearExistingPosts()
+	source, err := bs.openSource()
 	if err != nil {
 		result := &SyncResult{
 			Success:   false,
-			Message:   "기존 포스트 삭제 실패",
+			Message:   "Notion 데이터 소스 연결 실패",
 			Error:     err,
 			Duration:  time.Since(startTime),
 			Timestamp: time.Now(),
@@ -142,34 +146,29 @@ func (bs *BlogSyncer) SyncToBlog() *SyncResult {
 		bs.setResult(result)
 		return result
 	}
+	notion.Init(bs.config.ApiKey, source)
+	defer notion.Close()
 
-	// 동기화 실행
-	bs.updateStatus(true, "Notion에서 데이터 가져오는 중...")
-	var wg sync.WaitGroup
-	errCh := make(chan error, 10)
-
-	notion.HandleCollectionView(rootID, &wg, errCh)
+	// 이전 동기화의 글 상태. Chan 이 만든 파일만 고치거나 지우는 데에도 사용하므로 전체 동기화에서도 읽는다.
+	previous := bs.loadManifest()
+	if full && previous != nil {
+		previous = previous.Stale()
+	}
 
-	// 이미지 다운로드 대기
-	bs.updateStatus(true, "이미지 다운로드 중...")
-	go func() {
-		wg.Wait()
-		close(errCh)
-	}()
+	// 동기화 실행 (글 하나의 실패는 Report 에 기록하고 나머지 글은 배포)
+	bs.updateStatus(true, "Notion에서 데이터 가져오는 중...")
+	report, err := notion.HandleRoots(roots, previous)
 
-	var syncError error
-	for err := range errCh {
-		if err != nil {
-			syncError = err
-			break
-		}
+	// 이번에 쓴 글 파일은 배포에 실패해도 디스크에 남으므로, 배포 전에 manifest 를 저장해 다음 동기화에서 Chan 의 파일로 취급한다.
+	if report != nil {
+		bs.saveManifest(report.Manifest)
 	}
-
-	if syncError != nil {
+	if err != nil {
 		result := &SyncResult{
 			Success:   false,
 			Message:   "동기화 중 오류 발생",
-			Error:     syncError,
+			Error:     err,
+			Report:    report,
 			Duration:  time.Since(startTime),
 			Timestamp: time.Now(),
 		}
@@ -177,14 +176,31 @@ func (bs *BlogSyncer) SyncToBlog() *SyncResult {
 		return result
 	}
 
+	// 어떤 글도 사용하지 않는 이미지 (실패한 글의 이미지는 알 수 없으므로 모든 글을 내보낸 경우에만)
+	// clean_images 를 켠 경우에만 지우고, 아니면 지울 목록만 결과에 남긴다.
+	if len(report.Failures) == 0 {
+		if bs.config.CleanImages {
+			bs.updateStatus(true, "사용하지 않는 이미지 정리 중...")
+			report.RemovedImages, err = notion.RemoveOrphanedImages(roots, report.Manifest)
+		} else {
+			report.OrphanedImages, err = notion.OrphanedImages(roots, report.Manifest)
+		}
+		if err != nil {
+			log.Printf("Warning: could not clean up images: %v", err)
+		}
+	} else {
+		log.Printf("Skip image cleanup: %d page(s) failed", len(report.Failures))
+	}
+
 	// Git 커밋 및 푸시
 	bs.updateStatus(true, "블로그에 배포 중...")
-	err = bs.gitCommitAndPush()
+	err = bs.gitCommitAndPush(roots)
 	if err != nil {
 		result := &SyncResult{
 			Success:   false,
 			Message:   "Git 배포 실패",
 			Error:     err,
+			Report:    report,
 			Duration:  time.Since(startTime),
 			Timestamp: time.Now(),
 		}
@@ -193,36 +209,156 @@ func (bs *BlogSyncer) SyncToBlog() *SyncResult {
 	}
 
 	// 성공 결과
+	message := "블로그 동기화 완료"
+	if len(report.Failures) > 0 {
+		message = fmt.Sprintf("블로그 동기화 완료 (실패 %d개)", len(report.Failures))
+	}
 	result := &SyncResult{
-		Success:   true,
-		Message:   "블로그 동기화 완료",
-		Duration:  time.Since(startTime),
-		Timestamp: time.Now(),
+		Success:    true,
+		Message:    message,
+		Report:     report,
+		PostCount:  report.Exported(),
+		ImageCount: report.ImagesDownloaded(),
+		Duration:   time.Since(startTime),
+		Timestamp:  time.Now(),
 	}
 	bs.setResult(result)
 	return result
 }
 
-func (bs *BlogSyncer) clearExistingPosts() error {
-	postDir := bs.config.PostDir
-	files, err := filepath.Glob(filepath.Join(postDir, "*.md"))
+// loadManifest 이전 동기화의 manifest 를 읽습니다.
+// manifest 가 없거나(첫 동기화) 읽지 못한 경우에는 nil 을 반환합니다.
+func (bs *BlogSyncer) loadManifest() *notion.Manifest {
+	if bs.config.ManifestPath == "" {
+		return nil
+	}
+
+	manifest, err := notion.LoadManifest(bs.config.ManifestPath)
+	if err != nil {
+		log.Printf("Warning: could not read manifest %s (exporting all pages): %v", bs.config.ManifestPath, err)
+		return nil
+	}
+	if len(manifest.Pages) == 0 {
+		log.Printf("No manifest at %s: exporting all pages (existing posts are kept)", bs.config.ManifestPath)
+		return nil
+	}
+
+	return manifest
+}
+
+// saveManifest 다음 동기화에 사용할 manifest 를 저장합니다.
+func (bs *BlogSyncer) saveManifest(manifest *notion.Manifest) {
+	if bs.config.ManifestPath == "" {
+		return
+	}
+	if err := manifest.Save(bs.config.ManifestPath); err != nil {
+		log.Printf("Warning: could not write manifest %s (next sync exports all pages): %v", bs.config.ManifestPath, err)
+	}
+}
+
+// OrphanedImages 마지막 동기화의 manifest 기준으로 어떤 글도 사용하지 않는 이미지 파일과 페이지 폴더를 찾습니다.
+// dryRun 이 아니면 찾은 파일과 폴더를 지웁니다. (지운 내용은 다음 동기화에서 배포됨)
+// manifest 보다 나중에 내려받은 이미지가 있으면 manifest 에 없는 글의 이미지일 수 있으므로 정리하지 않습니다.
+func (bs *BlogSyncer) OrphanedImages(dryRun bool) ([]string, error) {
+	roots, err := bs.roots()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := bs.loadManifest()
+	if manifest == nil {
+		return nil, fmt.Errorf("no manifest at %s, run a sync first", bs.config.ManifestPath)
+	}
+
+	info, err := os.Stat(bs.config.ManifestPath)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := notion.LatestImageTime(roots)
+	if err != nil {
+		return nil, err
+	}
+	if latest.After(info.ModTime()) {
+		return nil, fmt.Errorf("manifest %s is older than the last export (%s), run a sync first", bs.config.ManifestPath, latest.Format("2006-01-02 15:04:05"))
+	}
+
+	if dryRun {
+		return notion.OrphanedImages(roots, manifest)
+	}
+	return notion.RemoveOrphanedImages(roots, manifest)
+}
+
+// openSource 설정된 source 에 맞는 Notion 데이터 소스를 생성합니다.
+func (bs *BlogSyncer) openSource() (notion.Source, error) {
+	switch bs.config.Source {
+	case "", notion.SourceSQLite:
+		return notion.NewSQLiteSource(bs.config.DBPath)
+	case notion.SourceAPI:
+		return notion.NewAPISource(notion.ApiBaseURL, bs.config.ApiKey), nil
+	case notion.SourceSnapshot:
+		return notion.NewSnapshotSource(bs.config.SnapshotDir)
+	default:
+		return nil, fmt.Errorf("unsupported source: %s", bs.config.Source)
+	}
+}
+
+// Snapshot 설정된 source(기본값 notion.db) 에서 내보낼 페이지, 블록, 스키마를 dir 에 JSON 으로 기록합니다.
+// 기록한 디렉토리는 source: snapshot 으로 지정하여 동일한 입력으로 다시 변환할 수 있습니다.
+func (bs *BlogSyncer) Snapshot(dir string) error {
+	roots, err := bs.roots()
 	if err != nil {
 		return err
 	}
 
-	for _, file := range files {
-		err = os.Remove(file)
+	source, err := bs.openSource()
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	return notion.WriteSnapshot(source, roots, dir)
+}
+
+// roots 설정의 루트 목록을 UUID 형식을 검증하여 notion.Root 로 변환합니다.
+func (bs *BlogSyncer) roots() ([]notion.Root, error) {
+	var roots []notion.Root
+
+	for _, rc := range bs.config.AllRoots() {
+		rootID, err := utils.CheckUUIDv4Format(rc.RootID)
 		if err != nil {
-			log.Printf("Warning: could not remove file %s: %v", file, err)
+			return nil, fmt.Errorf("%s: %w", rc.RootID, err)
 		}
+
+		roots = append(roots, notion.Root{
+			ID:                  rootID,
+			PostDir:             rc.PostDir,
+			ImgDir:              rc.ImgDir,
+			Statuses:            rc.Statuses,
+			IncludeSubPages:     rc.IncludeSubPages,
+			FrontMatter:         rc.FrontMatter,
+			Permalink:           rc.Permalink,
+			InlineMath:          rc.InlineMath,
+			BlockMath:           rc.BlockMath,
+			EmbedIncludes:       rc.EmbedIncludes,
+			CalloutPrompts:      rc.CalloutPrompts,
+			ColorStyle:          rc.ColorStyle,
+			Stylesheet:          rc.Stylesheet,
+			ExpandToggleHeaders: rc.ExpandToggleHeaders,
+			ImageCaptionStyle:   rc.ImageCaptionStyle,
+			FlattenColumns:      rc.FlattenColumns,
+		})
 	}
 
-	return nil
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no root configured")
+	}
+
+	return roots, nil
 }
 
-func (bs *BlogSyncer) gitCommitAndPush() error {
-	// blog 저장소 경로 추출 (post_directory의 상위 디렉토리)
-	repoPath := filepath.Dir(bs.config.PostDir)
+func (bs *BlogSyncer) gitCommitAndPush(roots []notion.Root) error {
+	// blog 저장소 경로 추출 (post_directory의 상위 디렉토리, 모든 루트는 같은 블로그 저장소에 있어야 함)
+	repoPath := filepath.Dir(roots[0].PostDir)
 
 	// Git 설정을 조정하여 큰 파일 업로드 문제 해결
 	// HTTP 버퍼 크기 증가 (기본값: 1MB -> 500MB)
@@ -0,0 +1,192 @@
+package sync
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shinychan95/Chan/utils"
+)
+
+const testRootID = "1519a0a9-70f1-444e-95b4-f6e6fac46131"
+
+// writeTestSnapshot 루트 페이지와 하위 페이지들로 이루어진 스냅샷을 기록합니다.
+func writeTestSnapshot(t *testing.T, dir string, subPages ...string) {
+	t.Helper()
+
+	content := []string{"t1"}
+	blocks := map[string]interface{}{
+		"t1": map[string]interface{}{"id": "t1", "type": "text", "properties": map[string]interface{}{"title": [][]string{{"본문"}}}, "last_edited_time": 1705314600000},
+	}
+	for _, id := range subPages {
+		content = append(content, id)
+		blocks[id] = map[string]interface{}{"id": id, "type": "page", "properties": map[string]interface{}{"title": [][]string{{id}}}, "created_time": 1705314600000, "last_edited_time": 1705314600000}
+	}
+	blocks[testRootID] = map[string]interface{}{"id": testRootID, "type": "page", "content": content, "properties": map[string]interface{}{"title": [][]string{{"Docs"}}}, "created_time": 1705314600000, "last_edited_time": 1705314600000}
+
+	for name, value := range map[string]interface{}{"blocks.json": blocks, "pages.json": map[string]interface{}{}, "schemas.json": map[string]interface{}{}} {
+		data, err := json.Marshal(value)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644))
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+// initTestBlog 원격 저장소(remote)로 배포할 수 있는 블로그 저장소를 만듭니다.
+func initTestBlog(t *testing.T, remote, blog string) {
+	t.Helper()
+
+	runGit(t, remote, "init", "--bare", "-b", "main")
+	runGit(t, blog, "init", "-b", "main")
+	runGit(t, blog, "config", "user.name", "test")
+	runGit(t, blog, "config", "user.email", "test@example.com")
+	runGit(t, blog, "remote", "add", "origin", remote)
+	runGit(t, blog, "commit", "--allow-empty", "-m", "init")
+	runGit(t, blog, "push", "-u", "origin", "main")
+}
+
+func TestSyncToBlogKeepsPostsWrittenBeforeFailedPush(t *testing.T) {
+	// Arrange
+	remote, blog, snapshotDir := t.TempDir(), t.TempDir(), t.TempDir()
+	initTestBlog(t, remote, blog)
+
+	postDir := filepath.Join(blog, "_posts")
+	require.NoError(t, os.MkdirAll(postDir, 0755))
+	syncer, err := NewBlogSyncerWithConfig(&utils.Config{
+		Source:          "snapshot",
+		SnapshotDir:     snapshotDir,
+		ApiKey:          "secret_test",
+		RootID:          testRootID,
+		PostDir:         postDir,
+		ImgDir:          filepath.Join(blog, "assets", "pages"),
+		IncludeSubPages: true,
+		ManifestPath:    filepath.Join(t.TempDir(), "manifest.json"),
+	})
+	require.NoError(t, err)
+
+	writeTestSnapshot(t, snapshotDir)
+	first := syncer.SyncToBlog(false)
+	require.True(t, first.Success, "%v", first.Error)
+
+	// 새 글을 쓴 뒤 배포에 실패한다.
+	writeTestSnapshot(t, snapshotDir, "Install")
+	runGit(t, blog, "remote", "set-url", "origin", filepath.Join(remote, "missing"))
+	failedPush := syncer.SyncToBlog(false)
+	runGit(t, blog, "remote", "set-url", "origin", remote)
+
+	// Act
+	retry := syncer.SyncToBlog(false)
+
+	// Assert
+	require.False(t, failedPush.Success)
+	assert.Equal(t, "Git 배포 실패", failedPush.Message)
+	assert.FileExists(t, filepath.Join(postDir, "2024-01-15-docs-install.md"))
+
+	require.NotNil(t, retry.Report)
+	assert.Empty(t, retry.Report.Failures)
+	assert.Equal(t, 2, retry.Report.Unchanged())
+}
+
+func TestSyncToBlogKeepsOrphanedImagesByDefault(t *testing.T) {
+	// Arrange
+	blog, snapshotDir := t.TempDir(), t.TempDir()
+	runGit(t, blog, "init", "-b", "main")
+
+	postDir := filepath.Join(blog, "_posts")
+	imgDir := filepath.Join(blog, "assets", "pages")
+	orphan := filepath.Join(imgDir, "2ab4c6d8-1111-4222-8333-944455566677")
+	require.NoError(t, os.MkdirAll(postDir, 0755))
+	require.NoError(t, os.MkdirAll(orphan, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(orphan, "a.png"), []byte("image"), 0644))
+
+	syncer, err := NewBlogSyncerWithConfig(&utils.Config{
+		Source:       "snapshot",
+		SnapshotDir:  snapshotDir,
+		ApiKey:       "secret_test",
+		RootID:       testRootID,
+		PostDir:      postDir,
+		ImgDir:       imgDir,
+		ManifestPath: filepath.Join(t.TempDir(), "manifest.json"),
+	})
+	require.NoError(t, err)
+	writeTestSnapshot(t, snapshotDir)
+
+	// Act
+	result := syncer.SyncToBlog(false)
+
+	// manifest 이후에 내려받은 이미지가 있으면 정리하지 않는다.
+	newer := filepath.Join(orphan, "b.png")
+	require.NoError(t, os.WriteFile(newer, []byte("image"), 0644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(newer, later, later))
+	_, staleErr := syncer.OrphanedImages(false)
+
+	// Assert
+	require.NotNil(t, result.Report)
+	assert.Equal(t, []string{orphan}, result.Report.OrphanedImages)
+	assert.Empty(t, result.Report.RemovedImages)
+	assert.DirExists(t, orphan)
+
+	require.Error(t, staleErr)
+	assert.Contains(t, staleErr.Error(), "older than the last export")
+	assert.FileExists(t, newer)
+}
+
+func TestSyncToBlogReportsRemovedPosts(t *testing.T) {
+	// Arrange
+	remote, blog, snapshotDir := t.TempDir(), t.TempDir(), t.TempDir()
+	initTestBlog(t, remote, blog)
+
+	postDir := filepath.Join(blog, "_posts")
+	require.NoError(t, os.MkdirAll(postDir, 0755))
+	reportPath := filepath.Join(t.TempDir(), "last-sync.json")
+	syncer, err := NewBlogSyncerWithConfig(&utils.Config{
+		Source:          "snapshot",
+		SnapshotDir:     snapshotDir,
+		ApiKey:          "secret_test",
+		RootID:          testRootID,
+		PostDir:         postDir,
+		ImgDir:          filepath.Join(blog, "assets", "pages"),
+		IncludeSubPages: true,
+		ReportPath:      reportPath,
+		ManifestPath:    filepath.Join(t.TempDir(), "manifest.json"),
+	})
+	require.NoError(t, err)
+
+	writeTestSnapshot(t, snapshotDir, "Install")
+	first := syncer.SyncToBlog(false)
+	require.True(t, first.Success, "%v", first.Error)
+
+	// 하위 페이지를 지운다.
+	writeTestSnapshot(t, snapshotDir)
+
+	// Act
+	result := syncer.SyncToBlog(false)
+
+	// Assert
+	require.True(t, result.Success, "%v", result.Error)
+	removed := filepath.Join(postDir, "2024-01-15-docs-install.md")
+	assert.NoFileExists(t, removed)
+
+	data, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	var report struct {
+		Removed []string `json:"removed"`
+	}
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, []string{removed}, report.Removed)
+}
@@ -4,16 +4,110 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 type Config struct {
+	Source      string `json:"source"` // sqlite(기본값) | api | snapshot
 	DBPath      string `json:"db_path"`
+	SnapshotDir string `json:"snapshot_directory"`
 	ApiKey      string `json:"api_key"`
 	PostDir     string `json:"post_directory"`
 	ImgDir      string `json:"image_directory"`
 	RootID      string `json:"root_id"`
-	GitHubToken string `json:"github_token"`
-	GitHubRepo  string `json:"github_repo"`
+	// 루트가 일반 페이지인 경우, 하위 페이지들도 각각 글로 내보낼지 여부
+	IncludeSubPages bool `json:"include_sub_pages"`
+	// 수식 구분자 ("..." 자리에 수식이 들어감, 예: "\\(...\\)")
+	InlineMath string `json:"inline_math"`
+	BlockMath  string `json:"block_math"`
+	// 단일 루트의 변환 설정 (roots 항목과 같은 의미)
+	EmbedIncludes       map[string]string `json:"embed_includes"`
+	CalloutPrompts      map[string]string `json:"callout_prompts"`
+	ColorStyle          string            `json:"color_style"`
+	Stylesheet          string            `json:"stylesheet"`
+	ExpandToggleHeaders bool              `json:"expand_toggle_headers"`
+	ImageCaptionStyle   string            `json:"image_caption_style"`
+	FlattenColumns      bool              `json:"flatten_columns"`
+	Roots               []RootConfig      `json:"roots"`
+	GitHubToken         string            `json:"github_token"`
+	GitHubRepo          string            `json:"github_repo"`
+	// 마지막 동기화의 글별 결과를 저장할 JSON 파일 (기본값: 설정 파일 폴더/last-sync.json)
+	ReportPath string `json:"report_path"`
+	// 바뀐 글만 다시 변환하기 위한 글 상태 파일 (기본값: 설정 파일 폴더/manifest.json)
+	ManifestPath string `json:"manifest_path"`
+	// 동기화할 때 어떤 글도 사용하지 않는 이미지를 지울지 여부 (기본값: 지우지 않고 결과에 목록만 남김)
+	CleanImages bool `json:"clean_images"`
+}
+
+// RootConfig 하나의 루트(데이터베이스 혹은 페이지)와 그 출력 설정입니다.
+// 예: 기술 블로그 → _posts, TIL → _til, 독서 노트 → _notes
+type RootConfig struct {
+	RootID          string            `json:"root_id"`
+	PostDir         string            `json:"post_directory"`
+	ImgDir          string            `json:"image_directory"`
+	Statuses        []string          `json:"statuses,omitempty"` // 기본값: Published, Archived
+	IncludeSubPages bool              `json:"include_sub_pages,omitempty"`
+	FrontMatter     map[string]string `json:"front_matter,omitempty"`
+	Permalink       string            `json:"permalink,omitempty"`   // 기본값: /posts/:title/
+	InlineMath      string            `json:"inline_math,omitempty"` // 기본값: $...$
+	BlockMath       string            `json:"block_math,omitempty"`  // 기본값: $$...$$
+	// 임베드 include 형식 (예: {"vimeo": "{% include embed/vimeo.html id=':id' %}"}), 빈 값이면 iframe 사용
+	EmbedIncludes map[string]string `json:"embed_includes,omitempty"`
+	// 콜아웃 색상 → Chirpy prompt (예: {"purple": "tip", "gray": "info"}), 빈 값이면 prompt 없음
+	CalloutPrompts map[string]string `json:"callout_prompts,omitempty"`
+	ColorStyle     string            `json:"color_style,omitempty"` // class(기본값) | inline | none
+	Stylesheet     string            `json:"stylesheet,omitempty"`  // 기본값: 블로그/assets/css/notion.css
+	// 토글 제목을 details 로 접지 않고 일반 제목과 내용으로 펼칠지 여부
+	ExpandToggleHeaders bool `json:"expand_toggle_headers,omitempty"`
+	// 이미지 캡션 형식: chirpy(기본값, 이미지 다음 줄의 _caption_) | figure(<figure><figcaption>)
+	ImageCaptionStyle string `json:"image_caption_style,omitempty"`
+	// 컬럼 레이아웃을 HTML 없이 위에서 아래로 이어 붙일지 여부 (HTML 을 제거하는 테마용)
+	FlattenColumns bool `json:"flatten_columns,omitempty"`
+}
+
+// MathPlaceholder 수식 구분자 설정에서 수식이 들어갈 자리
+const MathPlaceholder = "..."
+
+// ValidMathDelimiters 수식 구분자 설정이 비어 있거나 MathPlaceholder 를 포함하는지 확인합니다.
+func (rc RootConfig) ValidMathDelimiters() bool {
+	for _, delimiters := range []string{rc.InlineMath, rc.BlockMath} {
+		if delimiters != "" && !strings.Contains(delimiters, MathPlaceholder) {
+			return false
+		}
+	}
+	return true
+}
+
+// UsesNotionAPI Notion 공개 API 를 호출하는 데이터 소스인지 확인합니다. (api_key 가 필요한 경우)
+// notion.db 에서 읽는 경우에도 이미지와 파일의 다운로드 주소는 공개 API 로 가져오며, 스냅샷은 네트워크를 사용하지 않는다.
+func (c *Config) UsesNotionAPI() bool {
+	return c.Source != "snapshot"
+}
+
+// AllRoots 동기화할 루트 목록을 반환합니다.
+// 기존 단일 루트 설정(root_id, post_directory, image_directory)이 있으면 최상위의 변환 설정과 함께 첫 번째 루트로 포함합니다.
+func (c *Config) AllRoots() []RootConfig {
+	var roots []RootConfig
+	if c.RootID != "" {
+		roots = append(roots, RootConfig{
+			RootID:              c.RootID,
+			PostDir:             c.PostDir,
+			ImgDir:              c.ImgDir,
+			IncludeSubPages:     c.IncludeSubPages,
+			InlineMath:          c.InlineMath,
+			BlockMath:           c.BlockMath,
+			EmbedIncludes:       c.EmbedIncludes,
+			CalloutPrompts:      c.CalloutPrompts,
+			ColorStyle:          c.ColorStyle,
+			Stylesheet:          c.Stylesheet,
+			ExpandToggleHeaders: c.ExpandToggleHeaders,
+			ImageCaptionStyle:   c.ImageCaptionStyle,
+			FlattenColumns:      c.FlattenColumns,
+		})
+	}
+
+	return append(roots, c.Roots...)
 }
 
 func ReadConfig(configPath string) (*Config, error) {
@@ -38,15 +132,36 @@ func ReadConfig(configPath string) (*Config, error) {
 		return nil, err
 	}
 
-	// 만약 notion.db 경로값 없을 경우, 동적으로 파악
-	if cfg.DBPath == "" {
+	// 만약 notion.db 경로값 없을 경우, 동적으로 파악 (API 로 가져오는 경우 notion.db 가 필요없음)
+	usesDB := cfg.Source == "" || cfg.Source == "sqlite"
+	if usesDB && cfg.DBPath == "" {
 		cfg.DBPath = FindNotionDBPath()
 	}
 
+	if cfg.ManifestPath == "" {
+		cfg.ManifestPath = filepath.Join(filepath.Dir(configPath), "manifest.json")
+	}
+	if cfg.ReportPath == "" {
+		cfg.ReportPath = filepath.Join(filepath.Dir(configPath), "last-sync.json")
+	}
+
 	// Check if all fields are present
-	if cfg.DBPath == "" || cfg.ApiKey == "" || cfg.PostDir == "" || cfg.ImgDir == "" || cfg.RootID == "" {
+	if (usesDB && cfg.DBPath == "") || (cfg.Source == "snapshot" && cfg.SnapshotDir == "") || (cfg.UsesNotionAPI() && cfg.ApiKey == "") {
 		return nil, fmt.Errorf("missing required fields in config file: %s", configPath)
 	}
 
+	roots := cfg.AllRoots()
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("missing required fields in config file: %s", configPath)
+	}
+	for _, root := range roots {
+		if root.RootID == "" || root.PostDir == "" || root.ImgDir == "" {
+			return nil, fmt.Errorf("missing required fields in config file: %s (root: %s)", configPath, root.RootID)
+		}
+		if !root.ValidMathDelimiters() {
+			return nil, fmt.Errorf("math delimiters must contain %q: %s (root: %s)", MathPlaceholder, configPath, root.RootID)
+		}
+	}
+
 	return &cfg, nil
 }
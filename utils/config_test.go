@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigForwardsSingleRootOptions(t *testing.T) {
+	// Arrange
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{
+		"source": "api",
+		"api_key": "secret_test",
+		"root_id": "1519a0a9-70f1-444e-95b4-f6e6fac46131",
+		"post_directory": "blog/_posts",
+		"image_directory": "blog/assets/pages",
+		"inline_math": "\\(...\\)",
+		"embed_includes": {"vimeo": "{% include embed/vimeo.html id=':id' %}"},
+		"callout_prompts": {"purple": "tip"},
+		"color_style": "inline",
+		"stylesheet": "blog/assets/css/colors.css",
+		"expand_toggle_headers": true,
+		"image_caption_style": "figure",
+		"flatten_columns": true,
+		"roots": [{"root_id": "2ab4c6d8-1111-4222-8333-944455566677", "post_directory": "blog/_til", "image_directory": "blog/assets/til"}]
+	}`), 0644))
+
+	// Act
+	cfg, err := ReadConfig(configPath)
+
+	// Assert
+	require.NoError(t, err)
+	roots := cfg.AllRoots()
+	require.Len(t, roots, 2)
+	assert.Equal(t, RootConfig{
+		RootID:              "1519a0a9-70f1-444e-95b4-f6e6fac46131",
+		PostDir:             "blog/_posts",
+		ImgDir:              "blog/assets/pages",
+		InlineMath:          `\(...\)`,
+		EmbedIncludes:       map[string]string{"vimeo": "{% include embed/vimeo.html id=':id' %}"},
+		CalloutPrompts:      map[string]string{"purple": "tip"},
+		ColorStyle:          "inline",
+		Stylesheet:          "blog/assets/css/colors.css",
+		ExpandToggleHeaders: true,
+		ImageCaptionStyle:   "figure",
+		FlattenColumns:      true,
+	}, roots[0])
+	assert.Equal(t, RootConfig{RootID: "2ab4c6d8-1111-4222-8333-944455566677", PostDir: "blog/_til", ImgDir: "blog/assets/til"}, roots[1]) // roots 항목은 최상위 설정을 따르지 않는다.
+}
+
+func TestReadConfigRequiresApiKeyOnlyForNotionAPI(t *testing.T) {
+	// Arrange
+	write := func(source string) string {
+		configPath := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(configPath, []byte(`{
+			"source": "`+source+`",
+			"snapshot_directory": "snapshot",
+			"root_id": "1519a0a9-70f1-444e-95b4-f6e6fac46131",
+			"post_directory": "blog/_posts",
+			"image_directory": "blog/assets/pages"
+		}`), 0644))
+		return configPath
+	}
+
+	// Act
+	snapshot, snapshotErr := ReadConfig(write("snapshot"))
+	_, apiErr := ReadConfig(write("api"))
+
+	// Assert
+	require.NoError(t, snapshotErr)
+	assert.Empty(t, snapshot.ApiKey)
+	assert.Error(t, apiErr)
+}
+
+func TestReadConfigDefaultsStatePathsToConfigFolder(t *testing.T) {
+	// Arrange
+	configDir := t.TempDir()
+	configPath := filepath.Join(configDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{
+		"source": "snapshot",
+		"snapshot_directory": "snapshot",
+		"root_id": "1519a0a9-70f1-444e-95b4-f6e6fac46131",
+		"post_directory": "blog/_posts",
+		"image_directory": "blog/assets/pages"
+	}`), 0644))
+
+	// Act
+	cfg, err := ReadConfig(configPath)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(configDir, "manifest.json"), cfg.ManifestPath)
+	assert.Equal(t, filepath.Join(configDir, "last-sync.json"), cfg.ReportPath)
+}